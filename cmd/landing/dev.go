@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/server"
+	"github.com/elchemista/LandingGo/internal/watch"
+)
+
+const devPollInterval = 500 * time.Millisecond
+
+// watchSource invalidates the current generation's caches whenever files
+// beneath root change, so edits show up (and browsers reload) without
+// restarting the server. Only used in development mode.
+func watchSource(ctx context.Context, root string, handle *server.Handle, logger *slog.Logger) {
+	poller := watch.New(root, devPollInterval)
+	poller.Run(ctx, func(changed []string) {
+		logger.Info("source changed", "files", changed)
+		handle.Current().Invalidate(changed)
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
@@ -16,14 +17,19 @@ import (
 	"github.com/elchemista/LandingGo/build"
 	"github.com/elchemista/LandingGo/internal/assets"
 	"github.com/elchemista/LandingGo/internal/config"
+	"github.com/elchemista/LandingGo/internal/contact"
 	"github.com/elchemista/LandingGo/internal/log"
+	"github.com/elchemista/LandingGo/internal/outbox"
 	"github.com/elchemista/LandingGo/internal/server"
+	"github.com/elchemista/LandingGo/internal/spam"
 )
 
 const (
 	defaultAddr   = ":8080"
 	defaultConfig = "config.prod.json"
 	webRoot       = "web"
+
+	outboxDrainTimeout = 15 * time.Second
 )
 
 func main() {
@@ -52,15 +58,37 @@ func main() {
 		os.Exit(1)
 	}
 
-	srv, err := server.New(conf, src, logger, cfg.dev)
+	var (
+		handle     *server.Handle
+		ob         *outbox.Outbox
+		serverOpts = []server.Option{
+			server.WithTokenSecret(os.Getenv("FORM_TOKEN_SECRET")),
+			server.WithLimiters(spam.NewLimiters()),
+		}
+	)
+
+	if cfg.dataDir != "" {
+		ob, err = outbox.Open(filepath.Join(cfg.dataDir, "outbox"), func(form string) contact.Sender {
+			return handle.Current().Sender(form)
+		}, outbox.Options{Logger: logger})
+		if err != nil {
+			logger.Error("open outbox", "error", err)
+			os.Exit(1)
+		}
+		serverOpts = append(serverOpts, server.WithOutbox(ob))
+	}
+
+	srv, err := server.New(conf, src, logger, cfg.dev, serverOpts...)
 	if err != nil {
 		logger.Error("initialise server", "error", err)
 		os.Exit(1)
 	}
 
+	handle = server.NewHandle(srv)
+
 	httpSrv := &http.Server{
 		Addr:              cfg.addr,
-		Handler:           srv.Handler(),
+		Handler:           handle,
 		ReadHeaderTimeout: 5 * time.Second,
 		IdleTimeout:       120 * time.Second,
 	}
@@ -68,6 +96,28 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	reload := &reloader{
+		src:    src,
+		logger: logger,
+		dev:    cfg.dev,
+		handle: handle,
+		opts:   serverOpts,
+	}
+	if conf.Source() != "embedded" {
+		reload.path = conf.Source()
+	}
+	go reload.run(ctx)
+
+	if ob != nil {
+		go ob.Run(ctx)
+	}
+
+	if cfg.dev && src.Root() != "" {
+		go watchSource(ctx, src.Root(), handle, logger)
+	}
+
+	httpSrv.RegisterOnShutdown(func() { handle.Current().Close() })
+
 	done := make(chan struct{})
 
 	go func() {
@@ -79,6 +129,14 @@ func main() {
 			logger.Error("server shutdown", "error", err)
 		}
 
+		if ob != nil {
+			drainCtx, cancelDrain := context.WithTimeout(context.Background(), outboxDrainTimeout)
+			defer cancelDrain()
+			if err := ob.Drain(drainCtx); err != nil {
+				logger.Error("outbox drain", "error", err)
+			}
+		}
+
 		close(done)
 	}()
 
@@ -99,6 +157,7 @@ type runtimeConfig struct {
 	addr       string
 	logLevel   string
 	folder     string
+	dataDir    string
 	dev        bool
 }
 
@@ -134,14 +193,17 @@ func parseConfig() runtimeConfig {
 	logLevelDefault := envOrDefault("LOG_LEVEL", "info")
 	devDefault := envBool("DEV", false)
 	folderDefault := envOrDefault("FOLDER", "")
+	dataDirDefault := envOrDefault("DATA_DIR", "")
 
 	configFlag := &stringFlag{value: configDefault}
 	addrFlag := &stringFlag{value: addrDefault}
 	folderFlag := &stringFlag{value: folderDefault}
+	dataDirFlag := &stringFlag{value: dataDirDefault}
 
 	flag.Var(configFlag, "config", "path to configuration file")
 	flag.Var(addrFlag, "addr", "address to listen on (host:port)")
 	flag.Var(folderFlag, "folder", "path to the asset folder (overrides embedded assets)")
+	flag.Var(dataDirFlag, "data-dir", "directory for runtime state such as the contact outbox")
 	logLevel := flag.String("log-level", logLevelDefault, "log level (debug, info, warn, error)")
 	dev := flag.Bool("dev", devDefault, "run in development mode (serve assets from disk)")
 
@@ -152,6 +214,7 @@ func parseConfig() runtimeConfig {
 		addr:       addrFlag.value,
 		logLevel:   *logLevel,
 		folder:     folderFlag.value,
+		dataDir:    dataDirFlag.value,
 		dev:        *dev,
 	}
 }
@@ -221,8 +284,21 @@ func applyRuntimeOverrides(cfg *config.Config) {
 		return
 	}
 
-	if apiKey := strings.TrimSpace(os.Getenv("MAILGUN_API_KEY")); apiKey != "" {
-		cfg.Contact.Mailgun.APIKey = apiKey
+	// Secrets from the environment fill in whatever the configuration
+	// leaves blank, for the contact block and named forms alike.
+	cfg.FillSecrets(config.Secrets{
+		MailgunAPIKey: strings.TrimSpace(os.Getenv("MAILGUN_API_KEY")),
+		SMTPPassword:  os.Getenv("SMTP_PASSWORD"),
+		WebhookSecret: strings.TrimSpace(os.Getenv("CONTACT_WEBHOOK_SECRET")),
+		CaptchaSecret: strings.TrimSpace(os.Getenv("CAPTCHA_SECRET")),
+	})
+
+	if secret := os.Getenv("CSRF_SECRET"); secret != "" && cfg.Security.CSRF.Secret == "" {
+		cfg.Security.CSRF.Secret = secret
+	}
+
+	if env := strings.TrimSpace(os.Getenv("SITE_ENV")); env != "" {
+		cfg.Site.Environment = env
 	}
 }
 
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/assets"
+	"github.com/elchemista/LandingGo/internal/config"
+	"github.com/elchemista/LandingGo/internal/server"
+	"github.com/elchemista/LandingGo/internal/watch"
+)
+
+const configPollInterval = 2 * time.Second
+
+// reloader rebuilds the server generation whenever the configuration changes,
+// either on SIGHUP or when the backing file is modified on disk.
+type reloader struct {
+	path   string
+	src    *assets.Source
+	logger *slog.Logger
+	dev    bool
+	handle *server.Handle
+	opts   []server.Option
+
+	mu sync.Mutex
+}
+
+func (r *reloader) run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	if r.path != "" {
+		poller := watch.New(r.path, configPollInterval)
+		go poller.Run(ctx, func([]string) { r.reload("file changed") })
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.reload("SIGHUP")
+		}
+	}
+}
+
+// reload builds a new generation from the current configuration and swaps it
+// in. Invalid configurations are logged and the running generation is kept.
+func (r *reloader) reload(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	conf, err := r.load()
+	if err == nil {
+		err = conf.Validate(func(name string) bool { return r.src.PageExists(name) })
+	}
+
+	var srv *server.Server
+	if err == nil {
+		srv, err = server.New(conf, r.src, r.logger, r.dev, r.opts...)
+	}
+
+	if err != nil {
+		r.logger.Error("reload rejected", "reason", reason, "source", r.sourceName(), "error", err)
+		return
+	}
+
+	r.handle.Swap(srv)
+	r.logger.Info("configuration reloaded", "reason", reason, "source", r.sourceName())
+}
+
+func (r *reloader) load() (*config.Config, error) {
+	if r.path == "" {
+		conf, _, err := loadConfig("")
+		return conf, err
+	}
+
+	conf, err := config.Load(r.path)
+	if err != nil {
+		return nil, err
+	}
+	applyRuntimeOverrides(conf)
+	return conf, nil
+}
+
+func (r *reloader) sourceName() string {
+	if r.path == "" {
+		return "embedded"
+	}
+	return r.path
+}
@@ -24,12 +24,63 @@ type ManifestEntry struct {
 	Size    int64     `json:"size"`
 	MIME    string    `json:"mime"`
 	ModTime time.Time `json:"mod_time"`
+	// Original is set on fingerprinted copies and names the asset path the
+	// copy was derived from.
+	Original string `json:"original,omitempty"`
+	// Gzip describes a precompressed sibling produced at pack time.
+	Gzip *CompressedVariant `json:"gzip,omitempty"`
+	// Media lists the images and videos a page references. It is only set on
+	// page entries.
+	Media *PageMedia `json:"media,omitempty"`
+}
+
+// PageMedia describes the media referenced by a page, as used by the image
+// and video sitemap extensions. Local URLs are site-relative paths to the
+// original (not fingerprinted) asset.
+type PageMedia struct {
+	Images []string    `json:"images,omitempty"`
+	Videos []PageVideo `json:"videos,omitempty"`
+}
+
+// PageVideo is a video element found on a page.
+type PageVideo struct {
+	Content   string `json:"content"`
+	Thumbnail string `json:"thumbnail,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
+
+// CompressedVariant describes a precompressed copy of an asset.
+type CompressedVariant struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
 }
 
 // Manifest captures metadata for cache and ETag handling.
 type Manifest struct {
 	GeneratedAt time.Time                `json:"generated_at"`
 	Files       map[string]ManifestEntry `json:"files"`
+	// Fingerprints maps original asset paths to their content-hashed names.
+	Fingerprints map[string]string `json:"fingerprints,omitempty"`
+}
+
+// Fingerprint returns the content-hashed path recorded for an original asset.
+func (m *Manifest) Fingerprint(path string) (string, bool) {
+	if m == nil || m.Fingerprints == nil {
+		return "", false
+	}
+	hashed, ok := m.Fingerprints[path]
+	return hashed, ok
+}
+
+// IsFingerprinted reports whether path is a content-hashed asset name whose
+// bytes can never change.
+func (m *Manifest) IsFingerprinted(path string) bool {
+	if m == nil {
+		return false
+	}
+	entry, ok := m.Files[path]
+	return ok && entry.Original != ""
 }
 
 // LoadManifest reads and parses a manifest from the provided filesystem.
@@ -72,6 +123,12 @@ type CachedAsset struct {
 	LastModified time.Time
 	MIME         string
 	Size         int64
+	// Immutable is true for fingerprinted assets, which may be cached forever.
+	Immutable bool
+	// Gzip holds the precompressed representation when the packer produced
+	// one, with its own strong ETag.
+	Gzip     []byte
+	GzipETag string
 }
 
 // NewCache constructs a Cache backed by the provided filesystem.
@@ -98,7 +155,17 @@ func (c *Cache) Get(path string) (*CachedAsset, error) {
 		return v.(*CachedAsset), nil
 	}
 
-	body, err := fs.ReadFile(c.fs, path)
+	// Originals of fingerprinted assets are only packed under their hashed
+	// name; the un-hashed URL keeps working by reading the hashed copy.
+	name := path
+	if hashed, ok := c.manifest.Fingerprint(path); ok {
+		name = hashed
+	}
+
+	body, err := fs.ReadFile(c.fs, name)
+	if err != nil && name != path && errors.Is(err, fs.ErrNotExist) {
+		body, err = fs.ReadFile(c.fs, path)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -112,12 +179,23 @@ func (c *Cache) Get(path string) (*CachedAsset, error) {
 		LastModified: meta.LastModified,
 		MIME:         meta.MIME,
 		Size:         int64(len(body)),
+		Immutable:    c.manifest.IsFingerprinted(path),
 	}
 
 	if asset.ETag == "" {
 		asset.ETag = strongETag(body)
 	}
 
+	if meta.Gzip != nil {
+		if gz, err := fs.ReadFile(c.fs, meta.Gzip.Path); err == nil {
+			asset.Gzip = gz
+			asset.GzipETag = quoteETag(meta.Gzip.SHA256)
+			if asset.GzipETag == "" {
+				asset.GzipETag = strongETag(gz)
+			}
+		}
+	}
+
 	if asset.LastModified.IsZero() && c.modTimeFn != nil {
 		if mt, err := c.modTimeFn(path); err == nil {
 			asset.LastModified = mt.UTC()
@@ -173,15 +251,11 @@ func (c *Cache) lookupMeta(path string) assetMeta {
 		lm = c.manifest.GeneratedAt
 	}
 
-	etag := entry.SHA256
-	if etag != "" && !strings.HasPrefix(etag, "\"") {
-		etag = fmt.Sprintf("\"%s\"", etag)
-	}
-
 	return assetMeta{
-		ETag:         etag,
+		ETag:         quoteETag(entry.SHA256),
 		LastModified: lm.UTC(),
 		MIME:         entry.MIME,
+		Gzip:         entry.Gzip,
 	}
 }
 
@@ -189,6 +263,14 @@ type assetMeta struct {
 	ETag         string
 	LastModified time.Time
 	MIME         string
+	Gzip         *CompressedVariant
+}
+
+func quoteETag(hash string) string {
+	if hash == "" || strings.HasPrefix(hash, "\"") {
+		return hash
+	}
+	return fmt.Sprintf("\"%s\"", hash)
 }
 
 func strongETag(body []byte) string {
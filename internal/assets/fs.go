@@ -66,10 +66,22 @@ func NewDisk(root string) (*Source, error) {
 		return nil, errors.New("disk root must be a directory")
 	}
 
+	fsys := os.DirFS(root)
+
+	// A packed build directory carries a manifest; plain web/ folders do not.
+	var manifest *Manifest
+	if _, err := fs.Stat(fsys, manifestFile); err == nil {
+		manifest, err = LoadManifest(fsys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &Source{
-		FS:          os.DirFS(root),
+		FS:          fsys,
 		kind:        SourceDisk,
 		root:        root,
+		Manifest:    manifest,
 		GeneratedAt: time.Now().UTC(),
 	}, nil
 }
@@ -0,0 +1,89 @@
+package packer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/assets"
+)
+
+// minCompressSize skips tiny files where gzip framing outweighs any saving.
+const minCompressSize = 256
+
+// gzipVariant writes a maximally compressed sibling (<rel>.gz) for
+// compressible assets. It returns nil when the type is not compressible or
+// compression saves less than 10%, which is not worth a second copy.
+func gzipVariant(publicDir, rel string, data []byte) (*assets.CompressedVariant, error) {
+	if len(data) < minCompressSize || !compressible(mimeType(rel)) {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	gw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, fmt.Errorf("gzip %s: %w", rel, err)
+	}
+	// Keep the header free of names and timestamps so builds are reproducible.
+	gw.Header.ModTime = time.Time{}
+	if _, err := gw.Write(data); err != nil {
+		return nil, fmt.Errorf("gzip %s: %w", rel, err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("gzip %s: %w", rel, err)
+	}
+
+	if buf.Len()*10 > len(data)*9 {
+		return nil, nil
+	}
+
+	gzPath := rel + ".gz"
+	if err := writeOutputFile(filepath.Join(publicDir, filepath.FromSlash(gzPath)), buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+
+	return &assets.CompressedVariant{
+		Path:   gzPath,
+		SHA256: hex.EncodeToString(sum[:]),
+		Size:   int64(buf.Len()),
+	}, nil
+}
+
+func compressible(mimeType string) bool {
+	mt := strings.ToLower(strings.TrimSpace(mimeType))
+	if idx := strings.IndexByte(mt, ';'); idx >= 0 {
+		mt = strings.TrimSpace(mt[:idx])
+	}
+
+	if strings.HasPrefix(mt, "text/") {
+		return true
+	}
+
+	switch mt {
+	case "application/javascript", "application/json", "application/manifest+json", "application/xml",
+		"image/svg+xml", "image/x-icon", "font/ttf", "font/otf":
+		return true
+	default:
+		return false
+	}
+}
+
+// setGzipVariant attaches variant to the manifest entry for rel.
+func setGzipVariant(manifest *assets.Manifest, rel string, variant *assets.CompressedVariant) {
+	if variant == nil {
+		return
+	}
+	entry, ok := manifest.Files[rel]
+	if !ok {
+		return
+	}
+	entry.Gzip = variant
+	manifest.Files[rel] = entry
+}
@@ -0,0 +1,139 @@
+package packer
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// fingerprintLength is the number of hex characters of the content hash
+// embedded in fingerprinted filenames.
+const fingerprintLength = 8
+
+var (
+	assetFuncPattern = regexp.MustCompile(`\{\{[^}]*\basset\s+"([^"]+)"`)
+	assetCallPattern = regexp.MustCompile(`\{\{-?\s*asset\s+"([^"]+)"\s*-?\}\}`)
+	staticRefPattern = regexp.MustCompile(`/static/[^\s"'<>(){}\\,?#]+`)
+	cssURLPattern    = regexp.MustCompile(`url\(\s*(['"]?)([^'")]+)(['"]?)\s*\)`)
+	cssImportPattern = regexp.MustCompile(`@import\s+(['"])([^'"]+)(['"])`)
+)
+
+// fingerprintedName inserts the leading characters of sum before the file
+// extension, e.g. static/app.css -> static/app.3f9a1c2b.css.
+func fingerprintedName(assetPath, sum string) string {
+	if len(sum) > fingerprintLength {
+		sum = sum[:fingerprintLength]
+	}
+
+	dir, base := path.Split(assetPath)
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		return dir + base + "." + sum
+	}
+
+	return dir + stem + "." + sum + ext
+}
+
+// rewriteReferences replaces absolute /static/... references with their
+// fingerprinted equivalents.
+func rewriteReferences(data []byte, fingerprints map[string]string) []byte {
+	if len(fingerprints) == 0 {
+		return data
+	}
+
+	return staticRefPattern.ReplaceAllFunc(data, func(ref []byte) []byte {
+		if hashed, ok := fingerprints[strings.TrimPrefix(string(ref), "/")]; ok {
+			return []byte("/" + hashed)
+		}
+		return ref
+	})
+}
+
+func isStylesheet(assetPath string) bool {
+	return strings.EqualFold(path.Ext(assetPath), ".css")
+}
+
+// cssReferences lists the static assets a stylesheet references through url()
+// or @import, resolved relative to the stylesheet itself.
+func cssReferences(cssPath string, data []byte) []string {
+	seen := make(map[string]struct{})
+	var refs []string
+
+	for _, pattern := range []*regexp.Regexp{cssURLPattern, cssImportPattern} {
+		for _, match := range pattern.FindAllSubmatch(data, -1) {
+			resolved, ok := resolveCSSRef(cssPath, string(match[2]))
+			if !ok {
+				continue
+			}
+			if _, dup := seen[resolved]; dup {
+				continue
+			}
+			seen[resolved] = struct{}{}
+			refs = append(refs, resolved)
+		}
+	}
+
+	return refs
+}
+
+// rewriteCSS points url() and @import references at fingerprinted files while
+// keeping each reference's original (relative or absolute) form.
+func rewriteCSS(cssPath string, data []byte, fingerprints map[string]string) []byte {
+	if len(fingerprints) == 0 {
+		return data
+	}
+
+	rewrite := func(pattern *regexp.Regexp, in []byte) []byte {
+		return pattern.ReplaceAllFunc(in, func(match []byte) []byte {
+			sub := pattern.FindSubmatchIndex(match)
+			ref := string(match[sub[4]:sub[5]])
+
+			resolved, ok := resolveCSSRef(cssPath, ref)
+			if !ok {
+				return match
+			}
+			hashed, ok := fingerprints[resolved]
+			if !ok {
+				return match
+			}
+
+			clean, suffix := splitRefSuffix(strings.TrimSpace(ref))
+			replaced := strings.TrimSuffix(clean, path.Base(clean)) + path.Base(hashed) + suffix
+
+			out := make([]byte, 0, len(match)+len(replaced))
+			out = append(out, match[:sub[4]]...)
+			out = append(out, replaced...)
+			return append(out, match[sub[5]:]...)
+		})
+	}
+
+	data = rewrite(cssURLPattern, data)
+	return rewrite(cssImportPattern, data)
+}
+
+func resolveCSSRef(cssPath, ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", false
+	}
+
+	lower := strings.ToLower(ref)
+	if strings.HasPrefix(lower, "data:") || strings.HasPrefix(ref, "#") || strings.Contains(lower, "://") || strings.HasPrefix(ref, "//") {
+		return "", false
+	}
+
+	clean, _ := splitRefSuffix(ref)
+	if !strings.HasPrefix(clean, "/") {
+		clean = "/" + path.Join(path.Dir(cssPath), clean)
+	}
+
+	return normalizeAssetPath(path.Clean(clean))
+}
+
+func splitRefSuffix(ref string) (string, string) {
+	if idx := strings.IndexAny(ref, "?#"); idx >= 0 {
+		return ref[:idx], ref[idx:]
+	}
+	return ref, ""
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"go/format"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
@@ -20,6 +21,8 @@ import (
 
 	"github.com/elchemista/LandingGo/internal/assets"
 	"github.com/elchemista/LandingGo/internal/config"
+	"github.com/elchemista/LandingGo/internal/content"
+	"github.com/elchemista/LandingGo/internal/pages"
 )
 
 // Run executes the asset packing pipeline.
@@ -61,6 +64,7 @@ func (o *options) run() error {
 	}
 
 	publicDir := filepath.Join(o.buildDir, "public")
+	previous, _ := assets.LoadManifest(os.DirFS(publicDir))
 	if err := os.RemoveAll(publicDir); err != nil {
 		return fmt.Errorf("clean build directory: %w", err)
 	}
@@ -78,12 +82,21 @@ func (o *options) run() error {
 		return err
 	}
 
+	if err := o.copyDataFiles(publicDir, &manifest); err != nil {
+		return err
+	}
+
 	assetSet := make(map[string]struct{})
 	pageSet := uniquePages(cfg)
+	shared, err := o.sharedTemplates()
+	if err != nil {
+		return err
+	}
+	pageSet = append(pageSet, shared...)
+	pageFiles := make([]pageFile, 0, len(pageSet))
 
 	for _, page := range pageSet {
 		src := filepath.Join(o.webDir, "pages", page)
-		dst := filepath.Join(publicDir, "pages", page)
 
 		info, err := os.Stat(src)
 		if err != nil {
@@ -93,44 +106,82 @@ func (o *options) run() error {
 			return fmt.Errorf("stat page %s: %w", page, err)
 		}
 
-		if err := copyFile(src, dst); err != nil {
-			return err
-		}
-
 		data, err := os.ReadFile(src)
 		if err != nil {
 			return fmt.Errorf("read page %s: %w", page, err)
 		}
 
-		for _, asset := range collectAssets(data) {
+		// Markdown pages are scanned as rendered, so images written as
+		// ![alt](/static/...) are packed and listed in the sitemap.
+		scan := data
+		if pages.IsMarkdown(page) {
+			md, err := pages.ParseMarkdown(data)
+			if err != nil {
+				return fmt.Errorf("page %s: %w", page, err)
+			}
+			if _, err := os.Stat(filepath.Join(o.webDir, "pages", filepath.FromSlash(md.Layout))); err != nil {
+				return fmt.Errorf("page %s: layout %s not found", page, md.Layout)
+			}
+			scan = md.HTML
+		}
+
+		found, media := collectAssets(scan)
+		for _, asset := range found {
 			assetSet[asset] = struct{}{}
 		}
 
-		modTime := info.ModTime().UTC()
-		addManifestEntry(&manifest, filepath.ToSlash(filepath.Join("pages", page)), data, modTime)
+		pageFiles = append(pageFiles, pageFile{name: page, data: data, modTime: info.ModTime().UTC(), media: media})
 	}
 
-	for assetPath := range assetSet {
-		src := filepath.Join(o.webDir, filepath.FromSlash(assetPath))
-		dst := filepath.Join(publicDir, filepath.FromSlash(assetPath))
+	contentFiles, err := o.collectContent(cfg, assetSet)
+	if err != nil {
+		return err
+	}
 
-		info, err := os.Stat(src)
-		if err != nil {
-			return fmt.Errorf("stat asset %s: %w", assetPath, err)
-		}
+	ogImages, err := o.routeImages(cfg)
+	if err != nil {
+		return err
+	}
+	for _, asset := range ogImages {
+		assetSet[asset] = struct{}{}
+	}
 
-		if err := copyFile(src, dst); err != nil {
+	if err := o.collectStylesheetAssets(assetSet); err != nil {
+		return err
+	}
+
+	fingerprints, err := o.packAssets(publicDir, assetSet, &manifest)
+	if err != nil {
+		return err
+	}
+	manifest.Fingerprints = fingerprints
+
+	for _, page := range pageFiles {
+		rel := filepath.ToSlash(filepath.Join("pages", page.name))
+		data := rewriteReferences(page.data, fingerprints)
+
+		if err := writeOutputFile(filepath.Join(publicDir, filepath.FromSlash(rel)), data); err != nil {
 			return err
 		}
 
-		data, err := os.ReadFile(src)
-		if err != nil {
-			return fmt.Errorf("read asset %s: %w", assetPath, err)
+		addManifestEntry(&manifest, rel, data, page.modTime)
+		if page.media != nil {
+			entry := manifest.Files[rel]
+			entry.Media = page.media
+			manifest.Files[rel] = entry
 		}
+	}
 
-		addManifestEntry(&manifest, assetPath, data, info.ModTime().UTC())
+	for _, file := range contentFiles {
+		data := rewriteReferences(file.data, fingerprints)
+		if err := writeOutputFile(filepath.Join(publicDir, filepath.FromSlash(file.name)), data); err != nil {
+			return err
+		}
+		addManifestEntry(&manifest, file.name, data, file.modTime)
 	}
 
+	preserveModTimes(&manifest, previous)
+
 	manifestPath := filepath.Join(publicDir, assets.ManifestFilename)
 	if err := writeManifest(manifestPath, &manifest); err != nil {
 		return err
@@ -147,6 +198,162 @@ func (o *options) run() error {
 	return nil
 }
 
+type pageFile struct {
+	name    string
+	data    []byte
+	modTime time.Time
+	media   *assets.PageMedia
+}
+
+// collectStylesheetAssets adds assets referenced from packed stylesheets
+// (fonts, background images, imports) until no new references turn up.
+func (o *options) collectStylesheetAssets(assetSet map[string]struct{}) error {
+	queue := make([]string, 0, len(assetSet))
+	for assetPath := range assetSet {
+		queue = append(queue, assetPath)
+	}
+	sort.Strings(queue)
+
+	for len(queue) > 0 {
+		assetPath := queue[0]
+		queue = queue[1:]
+
+		if !isStylesheet(assetPath) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(o.webDir, filepath.FromSlash(assetPath)))
+		if err != nil {
+			return fmt.Errorf("read asset %s: %w", assetPath, err)
+		}
+
+		for _, ref := range cssReferences(assetPath, data) {
+			if _, ok := assetSet[ref]; ok {
+				continue
+			}
+			assetSet[ref] = struct{}{}
+			queue = append(queue, ref)
+		}
+	}
+
+	return nil
+}
+
+// packAssets writes every referenced asset under a content-hashed name and
+// returns the original -> fingerprinted path mapping. Stylesheets are packed
+// last, each after the stylesheets it references, so their references can
+// point at already fingerprinted files.
+func (o *options) packAssets(publicDir string, assetSet map[string]struct{}, manifest *assets.Manifest) (map[string]string, error) {
+	var list, sheets []string
+	for assetPath := range assetSet {
+		if isStylesheet(assetPath) {
+			sheets = append(sheets, assetPath)
+		} else {
+			list = append(list, assetPath)
+		}
+	}
+	sort.Strings(list)
+
+	sheets, err := o.stylesheetOrder(sheets, assetSet)
+	if err != nil {
+		return nil, err
+	}
+	list = append(list, sheets...)
+
+	fingerprints := make(map[string]string, len(list))
+
+	for _, assetPath := range list {
+		src := filepath.Join(o.webDir, filepath.FromSlash(assetPath))
+
+		info, err := os.Stat(src)
+		if err != nil {
+			return nil, fmt.Errorf("stat asset %s: %w", assetPath, err)
+		}
+
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("read asset %s: %w", assetPath, err)
+		}
+
+		if isStylesheet(assetPath) {
+			data = rewriteCSS(assetPath, data, fingerprints)
+		}
+
+		modTime := info.ModTime().UTC()
+		sum := addManifestEntry(manifest, assetPath, data, modTime)
+		hashed := fingerprintedName(assetPath, sum)
+
+		if err := writeOutputFile(filepath.Join(publicDir, filepath.FromSlash(hashed)), data); err != nil {
+			return nil, err
+		}
+
+		addManifestEntry(manifest, hashed, data, modTime)
+		entry := manifest.Files[hashed]
+		entry.Original = assetPath
+		manifest.Files[hashed] = entry
+
+		variant, err := gzipVariant(publicDir, hashed, data)
+		if err != nil {
+			return nil, err
+		}
+		setGzipVariant(manifest, assetPath, variant)
+		setGzipVariant(manifest, hashed, variant)
+
+		fingerprints[assetPath] = hashed
+	}
+
+	return fingerprints, nil
+}
+
+// stylesheetOrder sorts sheets so every stylesheet follows the packed
+// stylesheets it imports or references. A reference cycle is an error, since
+// no order could fingerprint it.
+func (o *options) stylesheetOrder(sheets []string, assetSet map[string]struct{}) ([]string, error) {
+	sort.Strings(sheets)
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(sheets))
+	ordered := make([]string, 0, len(sheets))
+
+	var visit func(sheet string, chain []string) error
+	visit = func(sheet string, chain []string) error {
+		switch state[sheet] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("stylesheet reference cycle: %s", strings.Join(append(chain, sheet), " -> "))
+		}
+		state[sheet] = visiting
+
+		data, err := os.ReadFile(filepath.Join(o.webDir, filepath.FromSlash(sheet)))
+		if err != nil {
+			return fmt.Errorf("read asset %s: %w", sheet, err)
+		}
+		for _, ref := range cssReferences(sheet, data) {
+			if _, ok := assetSet[ref]; !ok || !isStylesheet(ref) {
+				continue
+			}
+			if err := visit(ref, append(chain, sheet)); err != nil {
+				return err
+			}
+		}
+
+		state[sheet] = done
+		ordered = append(ordered, sheet)
+		return nil
+	}
+
+	for _, sheet := range sheets {
+		if err := visit(sheet, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
 func (o *options) copyRootFiles(publicDir string, manifest *assets.Manifest) error {
 	entries, err := os.ReadDir(o.webDir)
 	if err != nil {
@@ -180,12 +387,112 @@ func (o *options) copyRootFiles(publicDir string, manifest *assets.Manifest) err
 			return fmt.Errorf("read root asset %s: %w", name, err)
 		}
 
-		addManifestEntry(manifest, filepath.ToSlash(name), data, info.ModTime().UTC())
+		rel := filepath.ToSlash(name)
+		addManifestEntry(manifest, rel, data, info.ModTime().UTC())
+
+		variant, err := gzipVariant(publicDir, rel, data)
+		if err != nil {
+			return err
+		}
+		setGzipVariant(manifest, rel, variant)
+	}
+
+	return nil
+}
+
+// copyDataFiles packs the JSON files exposed to templates as .Extra. Files are
+// checked at pack time so a typo fails the build rather than the server.
+func (o *options) copyDataFiles(publicDir string, manifest *assets.Manifest) error {
+	dataDir := filepath.Join(o.webDir, pages.DataDir)
+
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read data directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+
+		src := filepath.Join(dataDir, name)
+		info, err := os.Stat(src)
+		if err != nil {
+			return fmt.Errorf("stat data file %s: %w", name, err)
+		}
+
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return fmt.Errorf("read data file %s: %w", name, err)
+		}
+
+		if !json.Valid(data) {
+			return fmt.Errorf("data file %s: invalid JSON", name)
+		}
+
+		rel := filepath.ToSlash(filepath.Join(pages.DataDir, name))
+		if err := writeOutputFile(filepath.Join(publicDir, filepath.FromSlash(rel)), data); err != nil {
+			return err
+		}
+
+		addManifestEntry(manifest, rel, data, info.ModTime().UTC())
 	}
 
 	return nil
 }
 
+// collectContent loads the entries of every collection route, so a broken
+// entry fails the build, and records the static assets they reference. Entry files are returned by path within the web root.
+func (o *options) collectContent(cfg *config.Config, assetSet map[string]struct{}) ([]pageFile, error) {
+	fsys := os.DirFS(o.webDir)
+	seen := make(map[string]struct{})
+
+	var files []pageFile
+	for _, route := range cfg.RoutesByPath() {
+		if !route.IsCollection() {
+			continue
+		}
+		if _, ok := seen[route.Collection]; ok {
+			continue
+		}
+		seen[route.Collection] = struct{}{}
+
+		entries, err := content.Load(fsys, route.Collection)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %w", route.Path, err)
+		}
+
+		for _, entry := range entries {
+			src := filepath.Join(o.webDir, filepath.FromSlash(entry.File))
+			info, err := os.Stat(src)
+			if err != nil {
+				return nil, fmt.Errorf("stat entry %s: %w", entry.File, err)
+			}
+			data, err := os.ReadFile(src)
+			if err != nil {
+				return nil, fmt.Errorf("read entry %s: %w", entry.File, err)
+			}
+
+			// Entries are Markdown or JSON rather than HTML, so every
+			// /static/ reference counts, the same ones rewriteReferences
+			// fingerprints.
+			for _, ref := range staticRefPattern.FindAll(data, -1) {
+				if asset, ok := normalizeAssetPath(string(ref)); ok {
+					assetSet[asset] = struct{}{}
+				}
+			}
+
+			files = append(files, pageFile{name: entry.File, data: data, modTime: info.ModTime().UTC()})
+		}
+	}
+
+	return files, nil
+}
+
 func (o *options) applyDefaults() {
 	if strings.TrimSpace(o.configPath) == "" {
 		o.configPath = "config.prod.json"
@@ -205,7 +512,7 @@ func uniquePages(cfg *config.Config) []string {
 	}
 
 	// Ensure error overrides are included if present.
-	for _, name := range []string{"404.html", "500.html"} {
+	for _, name := range []string{"403.html", "404.html", "500.html"} {
 		pages[name] = struct{}{}
 	}
 
@@ -222,6 +529,54 @@ func uniquePages(cfg *config.Config) []string {
 	return list
 }
 
+// routeImages returns the og_image assets declared in the config, failing when
+// one is missing from web/static so a broken social preview never ships.
+func (o *options) routeImages(cfg *config.Config) ([]string, error) {
+	var list []string
+	for _, route := range cfg.Routes {
+		asset, ok := normalizeAssetPath(route.OGImage)
+		if !ok {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(o.webDir, filepath.FromSlash(asset))); err != nil {
+			return nil, fmt.Errorf("route %s: og_image %s not found in static/", route.Path, route.OGImage)
+		}
+		list = append(list, asset)
+	}
+	return list, nil
+}
+
+// sharedTemplates lists the layouts and partials under web/pages. They are
+// packed alongside the routed pages because any page may reference them.
+func (o *options) sharedTemplates() ([]string, error) {
+	pagesDir := filepath.Join(o.webDir, "pages")
+
+	var list []string
+	for _, dir := range []string{pages.LayoutsDir, pages.PartialsDir} {
+		root := filepath.Join(pagesDir, dir)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(pagesDir, path)
+			if err != nil {
+				return err
+			}
+			list = append(list, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("walk %s: %w", dir, err)
+		}
+	}
+
+	sort.Strings(list)
+	return list, nil
+}
+
 func copyFile(src, dst string) error {
 	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
 		return fmt.Errorf("create directory for %s: %w", dst, err)
@@ -246,13 +601,50 @@ func copyFile(src, dst string) error {
 	return nil
 }
 
-func collectAssets(htmlBytes []byte) []string {
-	node, err := html.Parse(bytes.NewReader(htmlBytes))
+func writeOutputFile(dst string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("create directory for %s: %w", dst, err)
+	}
+	if err := os.WriteFile(dst, data, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", dst, err)
+	}
+	return nil
+}
+
+// collectAssets returns the local assets referenced by a page together with
+// the images and videos it shows, which feed the sitemap extensions.
+func collectAssets(htmlBytes []byte) ([]string, *assets.PageMedia) {
+	// Simple asset calls are replaced by their argument so the parser sees
+	// the path; otherwise the quotes inside the call would split the
+	// attribute value.
+	node, err := html.Parse(bytes.NewReader(assetCallPattern.ReplaceAll(htmlBytes, []byte("$1"))))
 	if err != nil {
-		return nil
+		return nil, nil
+	}
+
+	found := make(map[string]struct{})
+	media := &assets.PageMedia{}
+	seenMedia := make(map[string]struct{})
+
+	add := func(ref string) {
+		if normalized, ok := normalizeAssetPath(ref); ok {
+			found[normalized] = struct{}{}
+		}
+	}
+	addImage := func(ref string) {
+		if u, ok := mediaURL(ref); ok {
+			if _, dup := seenMedia[u]; !dup {
+				seenMedia[u] = struct{}{}
+				media.Images = append(media.Images, u)
+			}
+		}
 	}
 
-	assets := make(map[string]struct{})
+	// Paths passed to the asset template function are often hidden from the
+	// HTML parser because the call sits inside a quoted attribute.
+	for _, m := range assetFuncPattern.FindAllSubmatch(htmlBytes, -1) {
+		add(string(m[1]))
+	}
 
 	var walk func(*html.Node)
 	walk = func(n *html.Node) {
@@ -260,38 +652,36 @@ func collectAssets(htmlBytes []byte) []string {
 			tag := strings.ToLower(n.Data)
 			switch tag {
 			case "link":
-				ref := getAttr(n, "href")
-				if ref != "" {
-					if normalized, ok := normalizeAssetPath(ref); ok {
-						assets[normalized] = struct{}{}
-					}
+				if ref := getAttr(n, "href"); ref != "" {
+					add(ref)
 				}
 			case "script", "img", "source", "video", "audio", "track", "iframe", "image", "use":
 				if ref := getAttr(n, "src"); ref != "" {
-					if normalized, ok := normalizeAssetPath(ref); ok {
-						assets[normalized] = struct{}{}
+					add(ref)
+					if tag == "img" {
+						addImage(ref)
 					}
 				}
 				if tag == "video" {
 					if poster := getAttr(n, "poster"); poster != "" {
-						if normalized, ok := normalizeAssetPath(poster); ok {
-							assets[normalized] = struct{}{}
+						add(poster)
+					}
+					if video, ok := videoOf(n); ok {
+						if _, dup := seenMedia[video.Content]; !dup {
+							seenMedia[video.Content] = struct{}{}
+							media.Videos = append(media.Videos, video)
 						}
 					}
 				}
 				if srcset := getAttr(n, "srcset"); srcset != "" {
 					for _, ref := range parseSrcSet(srcset) {
-						if normalized, ok := normalizeAssetPath(ref); ok {
-							assets[normalized] = struct{}{}
-						}
+						add(ref)
 					}
 				}
 			case "meta":
 				if name := strings.ToLower(getAttr(n, "property")); name == "og:image" || name == "twitter:image" {
 					if content := getAttr(n, "content"); content != "" {
-						if normalized, ok := normalizeAssetPath(content); ok {
-							assets[normalized] = struct{}{}
-						}
+						add(content)
 					}
 				}
 			}
@@ -304,14 +694,66 @@ func collectAssets(htmlBytes []byte) []string {
 
 	walk(node)
 
-	list := make([]string, 0, len(assets))
-	for asset := range assets {
+	list := make([]string, 0, len(found))
+	for asset := range found {
 		list = append(list, asset)
 	}
 
 	sort.Strings(list)
 
-	return list
+	if len(media.Images) == 0 && len(media.Videos) == 0 {
+		media = nil
+	}
+
+	return list, media
+}
+
+// videoOf describes a video element. The content URL is its src or first
+// <source>; the title comes from the title or aria-label attribute.
+func videoOf(n *html.Node) (assets.PageVideo, bool) {
+	content, ok := mediaURL(getAttr(n, "src"))
+	if !ok {
+		for c := n.FirstChild; c != nil && !ok; c = c.NextSibling {
+			if c.Type == html.ElementNode && strings.EqualFold(c.Data, "source") {
+				content, ok = mediaURL(getAttr(c, "src"))
+			}
+		}
+	}
+	if !ok {
+		return assets.PageVideo{}, false
+	}
+
+	video := assets.PageVideo{Content: content}
+	if thumb, ok := mediaURL(getAttr(n, "poster")); ok {
+		video.Thumbnail = thumb
+	}
+	video.Title = getAttr(n, "title")
+	if video.Title == "" {
+		video.Title = getAttr(n, "aria-label")
+	}
+
+	return video, true
+}
+
+// mediaURL returns the URL recorded for an image or video reference: local
+// static assets as site-relative paths and remote media as absolute URLs.
+// Template expressions and data URIs are skipped.
+func mediaURL(ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.Contains(ref, "{{") {
+		return "", false
+	}
+
+	lower := strings.ToLower(ref)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return ref, true
+	}
+
+	if normalized, ok := normalizeAssetPath(ref); ok {
+		return "/" + normalized, true
+	}
+
+	return "", false
 }
 
 func getAttr(n *html.Node, key string) string {
@@ -376,21 +818,43 @@ func normalizeAssetPath(path string) (string, bool) {
 	return path, true
 }
 
-func addManifestEntry(manifest *assets.Manifest, relativePath string, data []byte, modTime time.Time) {
+// addManifestEntry records the file and returns its hex-encoded SHA-256.
+func addManifestEntry(manifest *assets.Manifest, relativePath string, data []byte, modTime time.Time) string {
 	if manifest.Files == nil {
 		manifest.Files = make(map[string]assets.ManifestEntry)
 	}
 
 	rel := filepath.ToSlash(relativePath)
 	hash := sha256.Sum256(data)
+	sum := hex.EncodeToString(hash[:])
 
 	manifest.Files[rel] = assets.ManifestEntry{
 		Path:    rel,
-		SHA256:  hex.EncodeToString(hash[:]),
+		SHA256:  sum,
 		Size:    int64(len(data)),
 		MIME:    mimeType(rel),
 		ModTime: modTime,
 	}
+
+	return sum
+}
+
+// preserveModTimes carries modification times over from the previous build
+// for files whose content hash is unchanged, so a fresh checkout or touched
+// file does not make every page look modified.
+func preserveModTimes(manifest, previous *assets.Manifest) {
+	if previous == nil {
+		return
+	}
+
+	for rel, entry := range manifest.Files {
+		old, ok := previous.Files[rel]
+		if !ok || old.SHA256 != entry.SHA256 || old.ModTime.IsZero() {
+			continue
+		}
+		entry.ModTime = old.ModTime
+		manifest.Files[rel] = entry
+	}
 }
 
 func mimeType(path string) string {
@@ -404,6 +868,10 @@ func mimeType(path string) string {
 		return "application/javascript"
 	case ".json":
 		return "application/json"
+	case ".webmanifest":
+		return "application/manifest+json"
+	case ".txt":
+		return "text/plain; charset=utf-8"
 	case ".map":
 		return "application/json"
 	case ".png":
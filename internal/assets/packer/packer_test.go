@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/elchemista/LandingGo/internal/assets"
 )
@@ -14,32 +17,54 @@ func TestCollectAssets(t *testing.T) {
 	html := []byte(`<!doctype html><html><head>
 <link rel="stylesheet" href="/static/app.css">
 <link rel="icon" href="/static/favicon.ico">
+<link rel="preload" as="font" href="{{asset "/static/fonts/inter.woff2"}}">
 <link rel="canonical" href="https://example.com/">
 </head><body>
 <img src="/static/img/logo.png" srcset="/static/img/logo.png 1x, https://cdn.example.com/logo@2x.png 2x">
 <script src="/static/app.js"></script>
-<video src="/static/video.mp4" poster="/static/poster.jpg"></video>
+<img src="https://cdn.example.com/hero.jpg" alt="">
+<img src="{{asset "/static/img/logo.png"}}" alt="">
+<video src="/static/video.mp4" poster="/static/poster.jpg" title="Tour"></video>
+<video poster="https://cdn.example.com/clip.jpg"><source src="https://cdn.example.com/clip.mp4" type="video/mp4"></video>
 </body></html>`)
 
-	assets := collectAssets(html)
+	found, media := collectAssets(html)
 	expected := []string{
 		"static/app.css",
 		"static/app.js",
 		"static/favicon.ico",
+		"static/fonts/inter.woff2",
 		"static/img/logo.png",
 		"static/poster.jpg",
 		"static/video.mp4",
 	}
 
-	if len(assets) != len(expected) {
-		t.Fatalf("expected %d assets, got %d: %#v", len(expected), len(assets), assets)
+	if len(found) != len(expected) {
+		t.Fatalf("expected %d assets, got %d: %#v", len(expected), len(found), found)
 	}
 
-	for i, asset := range assets {
+	for i, asset := range found {
 		if asset != expected[i] {
 			t.Fatalf("asset mismatch at %d: want %s got %s", i, expected[i], asset)
 		}
 	}
+
+	if media == nil {
+		t.Fatalf("expected page media")
+	}
+	wantImages := []string{"/static/img/logo.png", "https://cdn.example.com/hero.jpg"}
+	if strings.Join(media.Images, ",") != strings.Join(wantImages, ",") {
+		t.Fatalf("unexpected images: %#v", media.Images)
+	}
+	if len(media.Videos) != 2 ||
+		media.Videos[0] != (assets.PageVideo{Content: "/static/video.mp4", Thumbnail: "/static/poster.jpg", Title: "Tour"}) ||
+		media.Videos[1] != (assets.PageVideo{Content: "https://cdn.example.com/clip.mp4", Thumbnail: "https://cdn.example.com/clip.jpg"}) {
+		t.Fatalf("unexpected videos: %#v", media.Videos)
+	}
+
+	if _, media := collectAssets([]byte(`<p>No media</p>`)); media != nil {
+		t.Fatalf("expected nil media for a page without images, got %#v", media)
+	}
 }
 
 func TestRunGeneratesManifestAndEmbed(t *testing.T) {
@@ -75,8 +100,10 @@ func TestRunGeneratesManifestAndEmbed(t *testing.T) {
 		t.Fatalf("decode manifest: %v", err)
 	}
 
-	if len(manifest.Files) != 3 {
-		t.Fatalf("expected 3 manifest entries, got %d", len(manifest.Files))
+	// One page plus two assets, each asset also recorded under its
+	// fingerprinted name.
+	if len(manifest.Files) != 5 {
+		t.Fatalf("expected 5 manifest entries, got %d", len(manifest.Files))
 	}
 
 	if _, ok := manifest.Files["pages/home.html"]; !ok {
@@ -104,6 +131,399 @@ func TestRunGeneratesManifestAndEmbed(t *testing.T) {
 	}
 }
 
+func TestRunFingerprintsAssets(t *testing.T) {
+	tdir := t.TempDir()
+	webDir := filepath.Join(tdir, "web")
+	buildDir := filepath.Join(tdir, "build")
+
+	writeFile(t, filepath.Join(webDir, "pages", "home.html"), `<!doctype html><html><head><link rel="stylesheet" href="/static/app.css"><meta property="og:image" content="{{.BaseURL}}/static/img/og.png"></head><body></body></html>`)
+	writeFile(t, filepath.Join(webDir, "static", "app.css"), `@font-face{src:url("fonts/inter.woff2") format("woff2")}body{background:url(/static/img/og.png)}`)
+	writeFile(t, filepath.Join(webDir, "static", "fonts", "inter.woff2"), "WOFF2")
+	writeFile(t, filepath.Join(webDir, "static", "img", "og.png"), "PNG")
+
+	configPath := filepath.Join(tdir, "config.json")
+	writeFile(t, configPath, `{
+  "site": {"base_url": "https://example.com"},
+  "routes": [{"path": "/", "page": "home.html"}]
+}`)
+
+	if err := Run(configPath, webDir, buildDir); err != nil {
+		t.Fatalf("packer run: %v", err)
+	}
+
+	publicDir := filepath.Join(buildDir, "public")
+	manifest, err := assets.LoadManifest(os.DirFS(publicDir))
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+
+	for _, original := range []string{"static/app.css", "static/fonts/inter.woff2", "static/img/og.png"} {
+		hashed, ok := manifest.Fingerprint(original)
+		if !ok {
+			t.Fatalf("missing fingerprint for %s: %+v", original, manifest.Fingerprints)
+		}
+		if !manifest.IsFingerprinted(hashed) {
+			t.Fatalf("%s not marked as fingerprinted", hashed)
+		}
+		if _, err := os.Stat(filepath.Join(publicDir, filepath.FromSlash(hashed))); err != nil {
+			t.Fatalf("fingerprinted file %s not written: %v", hashed, err)
+		}
+	}
+
+	cssHashed, _ := manifest.Fingerprint("static/app.css")
+	css, err := os.ReadFile(filepath.Join(publicDir, filepath.FromSlash(cssHashed)))
+	if err != nil {
+		t.Fatalf("read css: %v", err)
+	}
+	fontHashed, _ := manifest.Fingerprint("static/fonts/inter.woff2")
+	imgHashed, _ := manifest.Fingerprint("static/img/og.png")
+	if !bytes.Contains(css, []byte(`url("fonts/`+filepath.Base(fontHashed)+`")`)) {
+		t.Fatalf("css font reference not rewritten: %s", css)
+	}
+	if !bytes.Contains(css, []byte("url(/"+imgHashed+")")) {
+		t.Fatalf("css image reference not rewritten: %s", css)
+	}
+
+	page, err := os.ReadFile(filepath.Join(publicDir, "pages", "home.html"))
+	if err != nil {
+		t.Fatalf("read page: %v", err)
+	}
+	if !bytes.Contains(page, []byte(`href="/`+cssHashed+`"`)) || !bytes.Contains(page, []byte("{{.BaseURL}}/"+imgHashed)) {
+		t.Fatalf("page references not rewritten: %s", page)
+	}
+}
+
+func TestRunFingerprintsStylesheetImports(t *testing.T) {
+	tdir := t.TempDir()
+	webDir := filepath.Join(tdir, "web")
+	buildDir := filepath.Join(tdir, "build")
+
+	writeFile(t, filepath.Join(webDir, "pages", "home.html"), `<link rel="stylesheet" href="/static/a.css">`)
+	writeFile(t, filepath.Join(webDir, "static", "a.css"), `@import "z.css";body{}`)
+	writeFile(t, filepath.Join(webDir, "static", "z.css"), `@import url("/static/m.css");p{}`)
+	writeFile(t, filepath.Join(webDir, "static", "m.css"), `h1{}`)
+
+	configPath := filepath.Join(tdir, "config.json")
+	writeFile(t, configPath, `{
+  "site": {"base_url": "https://example.com"},
+  "routes": [{"path": "/", "page": "home.html"}]
+}`)
+
+	if err := Run(configPath, webDir, buildDir); err != nil {
+		t.Fatalf("packer run: %v", err)
+	}
+
+	publicDir := filepath.Join(buildDir, "public")
+	manifest, err := assets.LoadManifest(os.DirFS(publicDir))
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+
+	read := func(original string) ([]byte, string) {
+		hashed, ok := manifest.Fingerprint(original)
+		if !ok {
+			t.Fatalf("missing fingerprint for %s", original)
+		}
+		data, err := os.ReadFile(filepath.Join(publicDir, filepath.FromSlash(hashed)))
+		if err != nil {
+			t.Fatalf("read %s: %v", hashed, err)
+		}
+		return data, hashed
+	}
+
+	a, _ := read("static/a.css")
+	z, zHashed := read("static/z.css")
+	_, mHashed := read("static/m.css")
+	if !bytes.Contains(a, []byte(`@import "`+path.Base(zHashed)+`"`)) {
+		t.Fatalf("import of a later-sorting stylesheet not rewritten: %s", a)
+	}
+	if !bytes.Contains(z, []byte(`url("/`+mHashed+`")`)) {
+		t.Fatalf("nested import not rewritten: %s", z)
+	}
+
+	writeFile(t, filepath.Join(webDir, "static", "m.css"), `@import "a.css";`)
+	if err := Run(configPath, webDir, buildDir); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected stylesheet cycle to fail the pack, got %v", err)
+	}
+}
+
+func TestRunPacksLayoutsAndPartials(t *testing.T) {
+	tdir := t.TempDir()
+	webDir := filepath.Join(tdir, "web")
+	buildDir := filepath.Join(tdir, "build")
+
+	writeFile(t, filepath.Join(webDir, "pages", "home.html"), `{{/* layout: base */}}{{define "content"}}<p>home</p>{{end}}`)
+	writeFile(t, filepath.Join(webDir, "pages", "_layouts", "base.html"), `<html><head>{{template "head" .}}</head><body>{{block "content" .}}{{end}}</body></html>`)
+	writeFile(t, filepath.Join(webDir, "pages", "_partials", "head.html"), `{{define "head"}}<link rel="stylesheet" href="/static/app.css">{{end}}`)
+	writeFile(t, filepath.Join(webDir, "static", "app.css"), "body{}")
+
+	configPath := filepath.Join(tdir, "config.json")
+	writeFile(t, configPath, `{
+  "site": {"base_url": "https://example.com"},
+  "routes": [{"path": "/", "page": "home.html"}]
+}`)
+
+	if err := Run(configPath, webDir, buildDir); err != nil {
+		t.Fatalf("packer run: %v", err)
+	}
+
+	publicDir := filepath.Join(buildDir, "public")
+	manifest, err := assets.LoadManifest(os.DirFS(publicDir))
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+
+	for _, name := range []string{"pages/_layouts/base.html", "pages/_partials/head.html"} {
+		if _, ok := manifest.Files[name]; !ok {
+			t.Fatalf("manifest missing %s", name)
+		}
+	}
+
+	cssHashed, ok := manifest.Fingerprint("static/app.css")
+	if !ok {
+		t.Fatalf("asset referenced only from a partial was not packed")
+	}
+
+	partial, err := os.ReadFile(filepath.Join(publicDir, "pages", "_partials", "head.html"))
+	if err != nil {
+		t.Fatalf("read partial: %v", err)
+	}
+	if !bytes.Contains(partial, []byte(`href="/`+cssHashed+`"`)) {
+		t.Fatalf("partial references not rewritten: %s", partial)
+	}
+}
+
+func TestRunPacksDataFiles(t *testing.T) {
+	tdir := t.TempDir()
+	webDir := filepath.Join(tdir, "web")
+	buildDir := filepath.Join(tdir, "build")
+
+	writeFile(t, filepath.Join(webDir, "pages", "home.html"), `<p>{{.Extra.brand.name}}</p>`)
+	writeFile(t, filepath.Join(webDir, "data", "brand.json"), `{"name":"Acme"}`)
+	writeFile(t, filepath.Join(webDir, "data", "README.md"), "not data")
+
+	configPath := filepath.Join(tdir, "config.json")
+	writeFile(t, configPath, `{
+  "site": {"base_url": "https://example.com"},
+  "routes": [{"path": "/", "page": "home.html"}]
+}`)
+
+	if err := Run(configPath, webDir, buildDir); err != nil {
+		t.Fatalf("packer run: %v", err)
+	}
+
+	publicDir := filepath.Join(buildDir, "public")
+	if _, err := os.Stat(filepath.Join(publicDir, "data", "brand.json")); err != nil {
+		t.Fatalf("data file not packed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(publicDir, "data", "README.md")); !os.IsNotExist(err) {
+		t.Fatalf("non-JSON file should be skipped, stat err = %v", err)
+	}
+
+	writeFile(t, filepath.Join(webDir, "data", "broken.json"), `{"name":`)
+	if err := Run(configPath, webDir, buildDir); err == nil || !strings.Contains(err.Error(), "broken.json") {
+		t.Fatalf("expected invalid data file to fail the pack, got %v", err)
+	}
+}
+
+func TestRunPacksMarkdownPages(t *testing.T) {
+	tdir := t.TempDir()
+	webDir := filepath.Join(tdir, "web")
+	buildDir := filepath.Join(tdir, "build")
+
+	writeFile(t, filepath.Join(webDir, "pages", "pricing.md"), "---\ntitle: Pricing\nlayout: docs\n---\n# Plans\n\n![Plans](/static/img/plans.png)\n")
+	writeFile(t, filepath.Join(webDir, "pages", "_layouts", "docs.html"), `<main>{{block "content" .}}{{end}}</main>`)
+	writeFile(t, filepath.Join(webDir, "static", "img", "plans.png"), "PNG")
+
+	configPath := filepath.Join(tdir, "config.json")
+	writeFile(t, configPath, `{
+  "site": {"base_url": "https://example.com"},
+  "routes": [{"path": "/pricing", "page": "pricing.md"}]
+}`)
+
+	if err := Run(configPath, webDir, buildDir); err != nil {
+		t.Fatalf("packer run: %v", err)
+	}
+
+	publicDir := filepath.Join(buildDir, "public")
+	manifest, err := assets.LoadManifest(os.DirFS(publicDir))
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+	hashed, ok := manifest.Fingerprint("static/img/plans.png")
+	if !ok {
+		t.Fatalf("image referenced from Markdown was not packed")
+	}
+	entry, ok := manifest.Files["pages/pricing.md"]
+	if !ok || entry.Media == nil || len(entry.Media.Images) != 1 {
+		t.Fatalf("expected Markdown image in page media, got %+v", entry)
+	}
+
+	packed, err := os.ReadFile(filepath.Join(publicDir, "pages", "pricing.md"))
+	if err != nil {
+		t.Fatalf("read packed page: %v", err)
+	}
+	if !bytes.Contains(packed, []byte("(/"+hashed+")")) {
+		t.Fatalf("Markdown references not rewritten: %s", packed)
+	}
+
+	writeFile(t, filepath.Join(webDir, "pages", "pricing.md"), "---\nlayout: missing\n---\nBody\n")
+	if err := Run(configPath, webDir, buildDir); err == nil || !strings.Contains(err.Error(), "_layouts/missing.html") {
+		t.Fatalf("expected missing layout to fail the pack, got %v", err)
+	}
+}
+
+func TestRunPacksContent(t *testing.T) {
+	tdir := t.TempDir()
+	webDir := filepath.Join(tdir, "web")
+	buildDir := filepath.Join(tdir, "build")
+
+	writeFile(t, filepath.Join(webDir, "pages", "case.html"), `<h1>{{.Entry.title}}</h1>{{.Content}}`)
+	writeFile(t, filepath.Join(webDir, "content", "work", "acme.md"), "---\ntitle: Acme\ncover: /static/img/cover.png\n---\nSee the [full shot](/static/img/shot.png).\n")
+	writeFile(t, filepath.Join(webDir, "static", "img", "cover.png"), "PNG")
+	writeFile(t, filepath.Join(webDir, "static", "img", "shot.png"), "PNG")
+
+	configPath := filepath.Join(tdir, "config.json")
+	writeFile(t, configPath, `{
+  "site": {"base_url": "https://example.com"},
+  "routes": [{"path": "/work/{slug}", "page": "case.html", "collection": "work"}]
+}`)
+
+	if err := Run(configPath, webDir, buildDir); err != nil {
+		t.Fatalf("packer run: %v", err)
+	}
+
+	publicDir := filepath.Join(buildDir, "public")
+	manifest, err := assets.LoadManifest(os.DirFS(publicDir))
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+	for _, asset := range []string{"static/img/cover.png", "static/img/shot.png"} {
+		if _, ok := manifest.Fingerprint(asset); !ok {
+			t.Fatalf("asset %s referenced from content was not packed", asset)
+		}
+	}
+
+	packed, err := os.ReadFile(filepath.Join(publicDir, "content", "work", "acme.md"))
+	if err != nil {
+		t.Fatalf("content entry not packed: %v", err)
+	}
+	if strings.Contains(string(packed), "/static/img/shot.png") {
+		t.Fatalf("expected content references to be fingerprinted, got %q", packed)
+	}
+
+	writeFile(t, filepath.Join(webDir, "content", "work", "broken.md"), "---\ntitle: [oops\n---\n")
+	if err := Run(configPath, webDir, buildDir); err == nil || !strings.Contains(err.Error(), "broken.md") {
+		t.Fatalf("expected invalid entry to fail the pack, got %v", err)
+	}
+}
+
+func TestRunPreservesUnchangedModTimes(t *testing.T) {
+	tdir := t.TempDir()
+	webDir := filepath.Join(tdir, "web")
+	buildDir := filepath.Join(tdir, "build")
+
+	homePath := filepath.Join(webDir, "pages", "home.html")
+	aboutPath := filepath.Join(webDir, "pages", "about.html")
+	writeFile(t, homePath, `<h1>Home</h1>`)
+	writeFile(t, aboutPath, `<h1>About</h1>`)
+
+	configPath := filepath.Join(tdir, "config.json")
+	writeFile(t, configPath, `{
+  "site": {"base_url": "https://example.com"},
+  "routes": [{"path": "/", "page": "home.html"}, {"path": "/about", "page": "about.html"}]
+}`)
+
+	original := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, p := range []string{homePath, aboutPath} {
+		if err := os.Chtimes(p, original, original); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+
+	if err := Run(configPath, webDir, buildDir); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+
+	// Touch both pages, but only change the content of one.
+	later := original.Add(48 * time.Hour)
+	writeFile(t, aboutPath, `<h1>About us</h1>`)
+	for _, p := range []string{homePath, aboutPath} {
+		if err := os.Chtimes(p, later, later); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+
+	if err := Run(configPath, webDir, buildDir); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+
+	manifest, err := assets.LoadManifest(os.DirFS(filepath.Join(buildDir, "public")))
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+
+	if got := manifest.Files["pages/home.html"].ModTime; !got.Equal(original) {
+		t.Fatalf("unchanged page should keep its mod time, got %v", got)
+	}
+	if got := manifest.Files["pages/about.html"].ModTime; !got.Equal(later) {
+		t.Fatalf("changed page should take the new mod time, got %v", got)
+	}
+}
+
+func TestRunValidatesOGImages(t *testing.T) {
+	tdir := t.TempDir()
+	webDir := filepath.Join(tdir, "web")
+	buildDir := filepath.Join(tdir, "build")
+
+	writeFile(t, filepath.Join(webDir, "pages", "home.html"), `<p>home</p>`)
+	writeFile(t, filepath.Join(webDir, "static", "img", "og.png"), "PNG")
+
+	configPath := filepath.Join(tdir, "config.json")
+	writeFile(t, configPath, `{
+  "site": {"base_url": "https://example.com"},
+  "routes": [{"path": "/", "page": "home.html", "og_image": "/static/img/og.png"}]
+}`)
+
+	if err := Run(configPath, webDir, buildDir); err != nil {
+		t.Fatalf("packer run: %v", err)
+	}
+
+	manifest, err := assets.LoadManifest(os.DirFS(filepath.Join(buildDir, "public")))
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+	if _, ok := manifest.Fingerprint("static/img/og.png"); !ok {
+		t.Fatalf("og image referenced only from config was not packed")
+	}
+
+	writeFile(t, configPath, `{
+  "site": {"base_url": "https://example.com"},
+  "routes": [{"path": "/", "page": "home.html", "og_image": "/static/img/missing.png"}]
+}`)
+
+	if err := Run(configPath, webDir, buildDir); err == nil || !strings.Contains(err.Error(), "missing.png") {
+		t.Fatalf("expected missing og image to fail the pack, got %v", err)
+	}
+}
+
+func TestFingerprintedName(t *testing.T) {
+	cases := map[string]string{
+		"static/app.css":         "static/app.0123abcd.css",
+		"static/app.min.js":      "static/app.min.0123abcd.js",
+		"static/img/hero.png":    "static/img/hero.0123abcd.png",
+		"static/site.manifest":   "static/site.0123abcd.manifest",
+		"static/fonts/.hidden":   "static/fonts/.hidden.0123abcd",
+		"static/fonts/no-suffix": "static/fonts/no-suffix.0123abcd",
+	}
+
+	for in, want := range cases {
+		if got := fingerprintedName(in, "0123abcdef"); got != want {
+			t.Fatalf("fingerprintedName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
 func mustMkdir(t *testing.T, path string) {
 	t.Helper()
 	if err := os.MkdirAll(path, 0o755); err != nil {
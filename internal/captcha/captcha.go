@@ -0,0 +1,117 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+// Errors returned by Verify. ErrUnavailable means the provider could not be
+// asked; the others mean the token was judged and refused.
+var (
+	ErrMissing     = errors.New("captcha token missing")
+	ErrRejected    = errors.New("captcha token rejected")
+	ErrLowScore    = errors.New("captcha score below threshold")
+	ErrUnavailable = errors.New("captcha verification unavailable")
+)
+
+// Verifier checks CAPTCHA tokens against the provider's siteverify API.
+// Turnstile, hCaptcha and reCAPTCHA share the same request and response
+// shape.
+type Verifier struct {
+	cfg    config.Captcha
+	client *http.Client
+}
+
+// response is the subset of the siteverify reply used by Verify.
+type response struct {
+	Success    bool     `json:"success"`
+	Score      *float64 `json:"score"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// New returns a Verifier for cfg, or nil when no provider is configured. When
+// client is nil a client with a ten second timeout is used.
+func New(cfg config.Captcha, client *http.Client) *Verifier {
+	if !cfg.Enabled() {
+		return nil
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Verifier{cfg: cfg, client: client}
+}
+
+// Field returns the form parameter carrying the token.
+func (v *Verifier) Field() string {
+	return v.cfg.ResponseField()
+}
+
+// Verify asks the provider whether token is valid for the client at
+// remoteIP. reCAPTCHA v3 scores below the configured threshold are refused.
+func (v *Verifier) Verify(ctx context.Context, token, remoteIP string) error {
+	if v == nil {
+		return nil
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ErrMissing
+	}
+	if v.cfg.Secret == "" {
+		return fmt.Errorf("%w: secret is not configured", ErrUnavailable)
+	}
+
+	form := url.Values{"secret": {v.cfg.Secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.cfg.Endpoint(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: unexpected status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	var result response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return fmt.Errorf("%w: decode response: %v", ErrUnavailable, err)
+	}
+
+	if !result.Success {
+		if len(result.ErrorCodes) > 0 {
+			return fmt.Errorf("%w: %s", ErrRejected, strings.Join(result.ErrorCodes, ", "))
+		}
+		return ErrRejected
+	}
+
+	// A v2 key, or a misconfigured one, answers without a score; it must
+	// not pass a v3 threshold.
+	if v.cfg.Provider == config.CaptchaReCaptcha && v.cfg.MinScore > 0 {
+		if result.Score == nil {
+			return fmt.Errorf("%w: response has no score", ErrLowScore)
+		}
+		if *result.Score < v.cfg.MinScore {
+			return fmt.Errorf("%w: %.2f", ErrLowScore, *result.Score)
+		}
+	}
+
+	return nil
+}
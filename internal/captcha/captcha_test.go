@@ -0,0 +1,77 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+func TestVerify(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("secret") != "shh" || r.PostForm.Get("remoteip") != "203.0.113.7" {
+			t.Errorf("unexpected verify request: %v %v", r.PostForm, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.PostForm.Get("response") {
+		case "good":
+			_, _ = w.Write([]byte(`{"success":true,"score":0.9}`))
+		case "bot":
+			_, _ = w.Write([]byte(`{"success":true,"score":0.1}`))
+		case "v2":
+			_, _ = w.Write([]byte(`{"success":true}`))
+		case "down":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_, _ = w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	cases := []struct {
+		provider string
+		token    string
+		want     error
+	}{
+		{config.CaptchaTurnstile, "good", nil},
+		{config.CaptchaTurnstile, "bot", nil}, // scores only apply to reCAPTCHA
+		{config.CaptchaHCaptcha, "forged", ErrRejected},
+		{config.CaptchaHCaptcha, " ", ErrMissing},
+		{config.CaptchaReCaptcha, "good", nil},
+		{config.CaptchaReCaptcha, "bot", ErrLowScore},
+		{config.CaptchaReCaptcha, "v2", ErrLowScore}, // no score cannot meet min_score
+		{config.CaptchaTurnstile, "v2", nil},
+		{config.CaptchaReCaptcha, "down", ErrUnavailable},
+	}
+
+	for _, tc := range cases {
+		v := New(config.Captcha{Provider: tc.provider, Secret: "shh", MinScore: 0.5, VerifyURL: ts.URL}, ts.Client())
+		if err := v.Verify(context.Background(), tc.token, "203.0.113.7"); !errors.Is(err, tc.want) {
+			t.Fatalf("%s %q: got %v, want %v", tc.provider, tc.token, err, tc.want)
+		}
+	}
+
+	noSecret := New(config.Captcha{Provider: config.CaptchaTurnstile, VerifyURL: ts.URL}, ts.Client())
+	if err := noSecret.Verify(context.Background(), "good", ""); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable without a secret, got %v", err)
+	}
+
+	if New(config.Captcha{}, nil) != nil {
+		t.Fatal("expected nil verifier without a provider")
+	}
+}
+
+func TestResponseFields(t *testing.T) {
+	for provider, want := range map[string]string{
+		config.CaptchaTurnstile: "cf-turnstile-response",
+		config.CaptchaHCaptcha:  "h-captcha-response",
+		config.CaptchaReCaptcha: "g-recaptcha-response",
+	} {
+		if got := New(config.Captcha{Provider: provider}, nil).Field(); got != want {
+			t.Fatalf("%s: got %q, want %q", provider, got, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CAPTCHA providers.
+const (
+	CaptchaTurnstile = "turnstile"
+	CaptchaHCaptcha  = "hcaptcha"
+	CaptchaReCaptcha = "recaptcha"
+)
+
+// DefaultCaptchaScore is the reCAPTCHA v3 threshold used when none is set.
+const DefaultCaptchaScore = 0.5
+
+// Captcha configures server-side verification of a CAPTCHA token.
+type Captcha struct {
+	// Provider is turnstile, hcaptcha or recaptcha (v3). Empty disables
+	// verification.
+	Provider string `json:"provider"`
+	// Secret is the provider's secret key (env: CAPTCHA_SECRET).
+	Secret string `json:"secret"`
+	// MinScore is the lowest accepted reCAPTCHA v3 score, from 0 to 1.
+	MinScore float64 `json:"min_score"`
+	// VerifyURL overrides the provider's verification endpoint.
+	VerifyURL string `json:"verify_url"`
+}
+
+// Enabled reports whether a provider is configured.
+func (c Captcha) Enabled() bool {
+	return c.Provider != ""
+}
+
+// Endpoint returns VerifyURL or the provider's default verification URL.
+func (c Captcha) Endpoint() string {
+	if c.VerifyURL != "" {
+		return c.VerifyURL
+	}
+	switch c.Provider {
+	case CaptchaTurnstile:
+		return "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	case CaptchaHCaptcha:
+		return "https://api.hcaptcha.com/siteverify"
+	case CaptchaReCaptcha:
+		return "https://www.google.com/recaptcha/api/siteverify"
+	default:
+		return ""
+	}
+}
+
+// ResponseField returns the form parameter the provider's widget submits.
+func (c Captcha) ResponseField() string {
+	switch c.Provider {
+	case CaptchaTurnstile:
+		return "cf-turnstile-response"
+	case CaptchaHCaptcha:
+		return "h-captcha-response"
+	default:
+		return "g-recaptcha-response"
+	}
+}
+
+func (c *Captcha) validate(prefix string) error {
+	c.Provider = strings.ToLower(strings.TrimSpace(c.Provider))
+	c.Secret = strings.TrimSpace(c.Secret)
+	c.VerifyURL = strings.TrimSpace(c.VerifyURL)
+
+	if !c.Enabled() {
+		return nil
+	}
+
+	switch c.Provider {
+	case CaptchaTurnstile, CaptchaHCaptcha, CaptchaReCaptcha:
+	default:
+		return fmt.Errorf("%s.captcha.provider %q is not supported (use turnstile, hcaptcha or recaptcha)", prefix, c.Provider)
+	}
+
+	if c.VerifyURL != "" && !isHTTPURL(c.VerifyURL) {
+		return fmt.Errorf("%s.captcha.verify_url must be an http(s) URL", prefix)
+	}
+
+	if c.MinScore < 0 || c.MinScore > 1 {
+		return fmt.Errorf("%s.captcha.min_score must be between 0 and 1", prefix)
+	}
+	if c.Provider == CaptchaReCaptcha && c.MinScore == 0 {
+		c.MinScore = DefaultCaptchaScore
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// SlugParam is the path segment a collection route fills with each entry's
+// slug, as in /work/{slug}.
+const SlugParam = "{slug}"
+
+var collectionNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// IsCollection reports whether the route renders collection entries.
+func (r Route) IsCollection() bool {
+	return r.Collection != ""
+}
+
+// EntryPath returns the path of the collection entry with the given slug.
+func (r Route) EntryPath(slug string) string {
+	return strings.Replace(r.Path, SlugParam, slug, 1)
+}
+
+func (rt *Route) validateCollection() error {
+	rt.Collection = strings.TrimSpace(rt.Collection)
+	params := strings.Count(rt.Path, "{")
+
+	if rt.Collection == "" {
+		if params > 0 {
+			return fmt.Errorf("route %s: path parameters require a collection", rt.Path)
+		}
+		return nil
+	}
+
+	if !collectionNamePattern.MatchString(rt.Collection) {
+		return fmt.Errorf("route %s: collection must contain only lowercase letters, digits, '-' or '_'", rt.Path)
+	}
+
+	slugSegment := false
+	for _, segment := range strings.Split(rt.Path, "/") {
+		if segment == SlugParam {
+			slugSegment = true
+		}
+	}
+	if params != 1 || !slugSegment {
+		return fmt.Errorf("route %s: collection routes need exactly one %s path segment", rt.Path, SlugParam)
+	}
+
+	if strings.EqualFold(path.Ext(rt.Page), ".md") {
+		return fmt.Errorf("route %s: collection routes need an HTML template, not a Markdown page", rt.Path)
+	}
+
+	if rt.Canonical != "" || len(rt.Alternates) > 0 {
+		return fmt.Errorf("route %s: canonical and alternates are not supported on collection routes", rt.Path)
+	}
+
+	return nil
+}
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -15,10 +18,17 @@ import (
 
 // Config represents the runtime configuration for the landing page server.
 type Config struct {
-	Site    Site                         `json:"site"`
-	Routes  []Route                      `json:"routes"`
+	Site      Site       `json:"site"`
+	Routes    []Route    `json:"routes"`
+	Redirects []Redirect `json:"redirects"`
+	// Headers maps path patterns to response headers; see HeaderDirectives.
 	Headers map[string]map[string]string `json:"headers"`
 	Contact Contact                      `json:"contact"`
+	// Forms declares additional named forms; see Form.
+	Forms    map[string]Form `json:"forms"`
+	Security Security        `json:"security"`
+
+	headerRules []headerRule
 
 	loadedAt time.Time
 	source   string
@@ -28,14 +38,49 @@ type Config struct {
 type Site struct {
 	BaseURL      string `json:"base_url"`
 	RobotsPolicy string `json:"robots_policy"`
+	// Vars are exposed to every template through PageData.Extra.
+	Vars map[string]any `json:"vars,omitempty"`
+	// Environment names the active entry in Environments (env: SITE_ENV).
+	Environment  string                 `json:"environment"`
+	Environments map[string]Environment `json:"environments"`
 }
 
+// Contact delivery backends.
+const (
+	BackendMailgun = "mailgun"
+	BackendSMTP    = "smtp"
+	BackendWebhook = "webhook"
+)
+
+// SMTP TLS modes.
+const (
+	SMTPStartTLS = "starttls"
+	SMTPImplicit = "implicit"
+	SMTPNoTLS    = "none"
+)
+
 // Contact describes contact-form delivery settings.
 type Contact struct {
-	Recipient string  `json:"recipient"`
-	From      string  `json:"from"`
-	Subject   string  `json:"subject"`
-	Mailgun   Mailgun `json:"mailgun"`
+	// Backend selects the delivery backend: mailgun (default), smtp or
+	// webhook.
+	Backend   string `json:"backend"`
+	Recipient string `json:"recipient"`
+	From      string `json:"from"`
+	// Subject may be a text/template over SubjectData, for example
+	// "Quote from {{.Fields.name}}".
+	Subject string `json:"subject"`
+	// CC lists addresses copied on every message.
+	CC      []string `json:"cc"`
+	Mailgun Mailgun  `json:"mailgun"`
+	SMTP    SMTP     `json:"smtp"`
+	Webhook Webhook  `json:"webhook"`
+	// Fields declares the form schema. When empty the classic name, email
+	// and message fields are used.
+	Fields []Field `json:"fields"`
+	// Spam configures bot defenses for the form.
+	Spam Spam `json:"spam"`
+	// Captcha enables server-side CAPTCHA verification.
+	Captcha Captcha `json:"captcha"`
 }
 
 // Mailgun holds credentials for Mailgun email delivery.
@@ -44,23 +89,76 @@ type Mailgun struct {
 	APIKey string `json:"api_key"`
 }
 
+// SMTP holds settings for delivery through an SMTP relay.
+type SMTP struct {
+	Host string `json:"host"`
+	// Port defaults to 587 for starttls, 465 for implicit and 25 for none.
+	Port int `json:"port"`
+	// TLS is starttls (default), implicit or none.
+	TLS      string `json:"tls"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Auth is plain (default) or login. It is only used with a username.
+	Auth string `json:"auth"`
+}
+
+// Webhook holds settings for delivery to an HTTP endpoint.
+type Webhook struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+	// Header names the signature header; defaults to X-Signature-256.
+	Header string `json:"header"`
+}
+
 func (c *Contact) normalize() {
 	if c == nil {
 		return
 	}
+	c.Backend = strings.ToLower(strings.TrimSpace(c.Backend))
 	c.Recipient = strings.TrimSpace(c.Recipient)
 	c.From = strings.TrimSpace(c.From)
 	c.Subject = strings.TrimSpace(c.Subject)
+	for i := range c.CC {
+		c.CC[i] = strings.TrimSpace(c.CC[i])
+	}
 	c.Mailgun.Domain = strings.TrimSpace(c.Mailgun.Domain)
 	c.Mailgun.APIKey = strings.TrimSpace(c.Mailgun.APIKey)
+	c.SMTP.Host = strings.TrimSpace(c.SMTP.Host)
+	c.SMTP.TLS = strings.ToLower(strings.TrimSpace(c.SMTP.TLS))
+	c.SMTP.Username = strings.TrimSpace(c.SMTP.Username)
+	c.SMTP.Auth = strings.ToLower(strings.TrimSpace(c.SMTP.Auth))
+	c.Webhook.URL = strings.TrimSpace(c.Webhook.URL)
+	c.Webhook.Secret = strings.TrimSpace(c.Webhook.Secret)
+	c.Webhook.Header = strings.TrimSpace(c.Webhook.Header)
+}
+
+// BackendName returns the configured backend, defaulting to Mailgun.
+func (c Contact) BackendName() string {
+	if c.Backend == "" {
+		return BackendMailgun
+	}
+	return c.Backend
 }
 
+// Enabled reports whether the selected backend has everything it needs to
+// deliver messages, including secrets that may be supplied at runtime.
 func (c Contact) Enabled() bool {
-	return c.Recipient != "" && c.From != "" && c.Mailgun.Domain != "" && c.Mailgun.APIKey != ""
+	switch c.BackendName() {
+	case BackendMailgun:
+		return c.Recipient != "" && c.From != "" && c.Mailgun.Domain != "" && c.Mailgun.APIKey != ""
+	case BackendSMTP:
+		return c.Recipient != "" && c.From != "" && c.SMTP.Host != "" && (c.SMTP.Username == "" || c.SMTP.Password != "")
+	case BackendWebhook:
+		return c.Webhook.URL != "" && c.Webhook.Secret != ""
+	default:
+		return false
+	}
 }
 
 func (c Contact) isZero() bool {
-	return c.Recipient == "" && c.From == "" && c.Subject == "" && c.Mailgun.Domain == "" && c.Mailgun.APIKey == ""
+	return c.Backend == "" && c.Recipient == "" && c.From == "" && c.Subject == "" &&
+		c.Mailgun == (Mailgun{}) && c.SMTP == (SMTP{}) && c.Webhook == (Webhook{}) && len(c.Fields) == 0 && len(c.CC) == 0 && c.Spam.isZero() &&
+		c.Captcha == (Captcha{})
 }
 
 // Route maps an HTTP path to a template page.
@@ -68,6 +166,43 @@ type Route struct {
 	Path  string `json:"path"`
 	Page  string `json:"page"`
 	Title string `json:"title"`
+	// Data is merged over site vars and data files for this route only.
+	Data map[string]any `json:"data,omitempty"`
+	// Collection names a directory under web/content whose entries the
+	// route renders. The path must contain a {slug} segment.
+	Collection string `json:"collection"`
+
+	// SEO metadata exposed to templates and honoured by the sitemap.
+	Description string `json:"description"`
+	// Canonical overrides the canonical URL; a path is resolved against
+	// site.base_url. Defaults to the route path.
+	Canonical string `json:"canonical"`
+	// OGImage is a /static/ path or absolute URL used for social previews.
+	OGImage string `json:"og_image"`
+	// NoIndex keeps the route out of search engines and the sitemap.
+	NoIndex bool   `json:"noindex"`
+	Lang    string `json:"lang"`
+	// Alternates maps hreflang values to the path or URL of each translation.
+	Alternates map[string]string `json:"alternates"`
+
+	// Sitemap set to false leaves the route out of sitemap.xml.
+	Sitemap *bool `json:"sitemap"`
+	// ChangeFreq and Priority are copied to the route's sitemap entry.
+	ChangeFreq string   `json:"changefreq"`
+	Priority   *float64 `json:"priority"`
+}
+
+// errorPages are rendered for failed requests and never listed in the sitemap.
+var errorPages = map[string]struct{}{"403.html": {}, "404.html": {}, "500.html": {}}
+
+// InSitemap reports whether the route belongs in sitemap.xml: it must not be
+// noindex, opted out, or an error page.
+func (r Route) InSitemap() bool {
+	if r.NoIndex || (r.Sitemap != nil && !*r.Sitemap) {
+		return false
+	}
+	_, isErrorPage := errorPages[path.Base(r.Page)]
+	return !isErrorPage
 }
 
 // Load reads the provided JSON configuration file and validates it.
@@ -126,7 +261,7 @@ func (c *Config) normalize() error {
 		for key, val := range hdrs {
 			clean[canonicalHeaderKey(key)] = strings.TrimSpace(val)
 		}
-		normalized[cleanPath(path)] = clean
+		normalized[cleanHeaderPattern(path)] = clean
 	}
 
 	c.Headers = normalized
@@ -193,6 +328,10 @@ func (c *Config) Validate(fsExists func(name string) bool) error {
 		return err
 	}
 
+	if err := c.validateEnvironments(); err != nil {
+		return err
+	}
+
 	if len(c.Routes) == 0 {
 		return errors.New("config.routes must contain at least one entry")
 	}
@@ -231,35 +370,180 @@ func (c *Config) Validate(fsExists func(name string) bool) error {
 			rt.Title = defaultTitleFromPage(rt.Page)
 		}
 
+		if err := rt.validateSEO(); err != nil {
+			return err
+		}
+
+		if err := rt.validateCollection(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.validateRedirects(seenPaths); err != nil {
+		return err
 	}
 
 	if err := c.validateContact(); err != nil {
 		return err
 	}
 
+	if err := c.validateForms(); err != nil {
+		return err
+	}
+
+	if err := c.validateSecurity(); err != nil {
+		return err
+	}
+
+	if err := c.validateHeaders(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+var langTagPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`)
+
+func (rt *Route) validateSEO() error {
+	rt.Description = strings.TrimSpace(rt.Description)
+	rt.Canonical = strings.TrimSpace(rt.Canonical)
+	rt.OGImage = strings.TrimSpace(rt.OGImage)
+	rt.Lang = strings.TrimSpace(rt.Lang)
+
+	if rt.Canonical != "" && !isPathOrHTTPURL(rt.Canonical) {
+		return fmt.Errorf("route %s: canonical must be a path or http(s) URL", rt.Path)
+	}
+
+	if rt.OGImage != "" && !strings.HasPrefix(rt.OGImage, "/static/") && !isHTTPURL(rt.OGImage) {
+		return fmt.Errorf("route %s: og_image must be a /static/ path or http(s) URL", rt.Path)
+	}
+
+	if rt.Lang != "" && !langTagPattern.MatchString(rt.Lang) {
+		return fmt.Errorf("route %s: lang %q is not a valid language tag", rt.Path, rt.Lang)
+	}
+
+	switch rt.ChangeFreq = strings.ToLower(strings.TrimSpace(rt.ChangeFreq)); rt.ChangeFreq {
+	case "", "always", "hourly", "daily", "weekly", "monthly", "yearly", "never":
+	default:
+		return fmt.Errorf("route %s: changefreq %q is not a sitemap frequency", rt.Path, rt.ChangeFreq)
+	}
+
+	if rt.Priority != nil && (*rt.Priority < 0 || *rt.Priority > 1) {
+		return fmt.Errorf("route %s: priority must be between 0.0 and 1.0", rt.Path)
+	}
+
+	for hreflang, target := range rt.Alternates {
+		if hreflang != "x-default" && !langTagPattern.MatchString(hreflang) {
+			return fmt.Errorf("route %s: alternate %q is not a valid hreflang", rt.Path, hreflang)
+		}
+		if !isPathOrHTTPURL(target) {
+			return fmt.Errorf("route %s: alternate %s must be a path or http(s) URL", rt.Path, hreflang)
+		}
+	}
+
+	return nil
+}
+
+func isPathOrHTTPURL(s string) bool {
+	return (strings.HasPrefix(s, "/") && !strings.HasPrefix(s, "//")) || isHTTPURL(s)
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
 func (c *Config) validateContact() error {
-	contact := c.Contact
-	if contact.isZero() {
+	if c.Contact.isZero() {
 		return nil
 	}
+	return c.Contact.validate("contact")
+}
+
+// validate checks the delivery settings and field schema, filling in
+// defaults. prefix names the block in error messages.
+func (c *Contact) validate(prefix string) error {
+	switch c.BackendName() {
+	case BackendMailgun:
+		if c.Recipient == "" || c.From == "" || c.Mailgun.Domain == "" {
+			return fmt.Errorf("%s configuration is incomplete", prefix)
+		}
+		if strings.Contains(c.Mailgun.Domain, "://") {
+			return fmt.Errorf("%s.mailgun.domain must not include a URL scheme", prefix)
+		}
+	case BackendSMTP:
+		if c.Recipient == "" || c.From == "" || c.SMTP.Host == "" {
+			return fmt.Errorf("%s configuration is incomplete", prefix)
+		}
+		if err := c.SMTP.validate(prefix); err != nil {
+			return err
+		}
+	case BackendWebhook:
+		if c.Webhook.URL == "" {
+			return fmt.Errorf("%s.webhook.url is required", prefix)
+		}
+		if !isHTTPURL(c.Webhook.URL) {
+			return fmt.Errorf("%s.webhook.url must be an http(s) URL", prefix)
+		}
+		if c.Webhook.Header == "" {
+			c.Webhook.Header = "X-Signature-256"
+		}
+	default:
+		return fmt.Errorf("%s.backend %q is not supported (use mailgun, smtp or webhook)", prefix, c.Backend)
+	}
 
-	if contact.Recipient == "" || contact.From == "" || contact.Mailgun.Domain == "" {
-		return errors.New("contact configuration is incomplete")
+	if c.Recipient != "" && !strings.Contains(c.Recipient, "@") {
+		return fmt.Errorf("%s.recipient must be a valid email address", prefix)
 	}
 
-	if !strings.Contains(contact.Recipient, "@") {
-		return errors.New("contact.recipient must be a valid email address")
+	if c.From != "" && !strings.Contains(c.From, "@") {
+		return fmt.Errorf("%s.from must be a valid email address", prefix)
 	}
 
-	if !strings.Contains(contact.From, "@") {
-		return errors.New("contact.from must be a valid email address")
+	if err := validateFields(prefix, c.Fields); err != nil {
+		return err
 	}
 
-	if strings.Contains(contact.Mailgun.Domain, "://") {
-		return errors.New("contact.mailgun.domain must not include a URL scheme")
+	if err := c.Spam.validate(prefix, c.FormFields()); err != nil {
+		return err
+	}
+
+	return c.Captcha.validate(prefix)
+}
+
+func (s *SMTP) validate(prefix string) error {
+	if _, _, err := net.SplitHostPort(s.Host); err == nil || strings.Contains(s.Host, "://") {
+		return fmt.Errorf("%s.smtp.host must be a bare host name; set the port separately", prefix)
+	}
+
+	switch s.TLS {
+	case "":
+		s.TLS = SMTPStartTLS
+	case SMTPStartTLS, SMTPImplicit, SMTPNoTLS:
+	default:
+		return fmt.Errorf("%s.smtp.tls %q is not supported (use starttls, implicit or none)", prefix, s.TLS)
+	}
+
+	if s.Port == 0 {
+		switch s.TLS {
+		case SMTPImplicit:
+			s.Port = 465
+		case SMTPNoTLS:
+			s.Port = 25
+		default:
+			s.Port = 587
+		}
+	}
+	if s.Port < 1 || s.Port > 65535 {
+		return fmt.Errorf("%s.smtp.port must be between 1 and 65535", prefix)
+	}
+
+	switch s.Auth {
+	case "":
+		s.Auth = "plain"
+	case "plain", "login":
+	default:
+		return fmt.Errorf("%s.smtp.auth %q is not supported (use plain or login)", prefix, s.Auth)
 	}
 
 	return nil
@@ -282,28 +566,6 @@ func (c *Config) validateSite() error {
 	return nil
 }
 
-// HeaderDirectives returns the configured headers for a specific route path.
-func (c *Config) HeaderDirectives(path string) map[string]string {
-	if c == nil || c.Headers == nil {
-		return nil
-	}
-
-	path = cleanPath(path)
-
-	headers := c.Headers[path]
-
-	if len(headers) == 0 {
-		return nil
-	}
-
-	copy := make(map[string]string, len(headers))
-	for k, v := range headers {
-		copy[k] = v
-	}
-
-	return copy
-}
-
 // cleanPath ensures deterministic path representation.
 func cleanPath(p string) string {
 	if p == "" {
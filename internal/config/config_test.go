@@ -138,3 +138,581 @@ func TestValidateContactSuccess(t *testing.T) {
 		t.Fatalf("expected contact validation success, got %v", err)
 	}
 }
+
+func TestValidateContactBackends(t *testing.T) {
+	base := Contact{Recipient: "owner@example.com", From: "no-reply@example.com"}
+
+	cases := []struct {
+		name    string
+		mutate  func(*Contact)
+		want    string
+		enabled bool
+	}{
+		{"smtp defaults", func(c *Contact) {
+			c.Backend = "SMTP"
+			c.SMTP = SMTP{Host: "smtp.example.com", Username: "user", Password: "pw"}
+		}, "", true},
+		{"smtp without password", func(c *Contact) {
+			c.Backend = BackendSMTP
+			c.SMTP = SMTP{Host: "smtp.example.com", Username: "user"}
+		}, "", false},
+		{"smtp host with port", func(c *Contact) {
+			c.Backend = BackendSMTP
+			c.SMTP = SMTP{Host: "smtp.example.com:587"}
+		}, "contact.smtp.host", false},
+		{"smtp tls mode", func(c *Contact) {
+			c.Backend = BackendSMTP
+			c.SMTP = SMTP{Host: "smtp.example.com", TLS: "ssl"}
+		}, "contact.smtp.tls", false},
+		{"smtp auth", func(c *Contact) {
+			c.Backend = BackendSMTP
+			c.SMTP = SMTP{Host: "smtp.example.com", Auth: "cram-md5"}
+		}, "contact.smtp.auth", false},
+		{"webhook", func(c *Contact) {
+			*c = Contact{Backend: BackendWebhook, Webhook: Webhook{URL: "https://hooks.example.com/contact", Secret: "s"}}
+		}, "", true},
+		{"webhook url", func(c *Contact) {
+			*c = Contact{Backend: BackendWebhook, Webhook: Webhook{URL: "ftp://hooks.example.com"}}
+		}, "contact.webhook.url", false},
+		{"unknown backend", func(c *Contact) { c.Backend = "sendgrid" }, "contact.backend", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			contact := base
+			tc.mutate(&contact)
+			cfg := &Config{
+				Site:    Site{BaseURL: "http://localhost:8080"},
+				Routes:  []Route{{Path: "/", Page: "home.html"}},
+				Contact: contact,
+			}
+			_ = cfg.normalize()
+
+			err := cfg.Validate(func(string) bool { return true })
+			if tc.want != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.want) {
+					t.Fatalf("expected %q error, got %v", tc.want, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected validation error: %v", err)
+			}
+			if cfg.Contact.Enabled() != tc.enabled {
+				t.Fatalf("Enabled() = %v, want %v", cfg.Contact.Enabled(), tc.enabled)
+			}
+		})
+	}
+}
+
+func TestValidateContactSMTPDefaults(t *testing.T) {
+	cfg := &Config{
+		Site:    Site{BaseURL: "http://localhost:8080"},
+		Routes:  []Route{{Path: "/", Page: "home.html"}},
+		Contact: Contact{Backend: BackendSMTP, Recipient: "owner@example.com", From: "no-reply@example.com", SMTP: SMTP{Host: "smtp.example.com", TLS: SMTPImplicit}},
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if cfg.Contact.SMTP.Port != 465 || cfg.Contact.SMTP.Auth != "plain" {
+		t.Fatalf("unexpected defaults: %+v", cfg.Contact.SMTP)
+	}
+}
+
+func TestValidateContactFields(t *testing.T) {
+	email := Field{Name: "email", Type: "EMAIL", Required: true}
+
+	cases := []struct {
+		name   string
+		fields []Field
+		want   string
+	}{
+		{"valid", []Field{email, {Name: "budget", Type: FieldSelect, Options: []string{"a"}}, {Name: "code", Pattern: `[A-Z]+`}}, ""},
+		{"missing email", []Field{{Name: "name", Required: true}}, "required email field"},
+		{"bad name", []Field{email, {Name: "first name"}}, "first name"},
+		{"duplicate", []Field{email, {Name: "email", Type: FieldText}}, "duplicate"},
+		{"type", []Field{email, {Name: "file", Type: "file"}}, "unsupported type"},
+		{"lengths", []Field{email, {Name: "name", MinLength: 10, MaxLength: 5}}, "min_length"},
+		{"pattern", []Field{email, {Name: "code", Pattern: "("}}, "pattern"},
+		{"select options", []Field{email, {Name: "budget", Type: FieldSelect}}, "options"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Site:   Site{BaseURL: "http://localhost:8080"},
+				Routes: []Route{{Path: "/", Page: "home.html"}},
+				Contact: Contact{
+					Recipient: "owner@example.com",
+					From:      "no-reply@example.com",
+					Mailgun:   Mailgun{Domain: "mg.example.com"},
+					Fields:    tc.fields,
+				},
+			}
+
+			err := cfg.Validate(func(string) bool { return true })
+			if tc.want != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.want) {
+					t.Fatalf("expected %q error, got %v", tc.want, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected validation error: %v", err)
+			}
+			fields := cfg.Contact.FormFields()
+			if fields[0].Type != FieldEmail || fields[2].Type != FieldText {
+				t.Fatalf("field types not normalised: %+v", fields)
+			}
+			if re := fields[2].Regexp(); re == nil || re.MatchString("ABC1") || !re.MatchString("ABC") {
+				t.Fatalf("pattern should match the whole value")
+			}
+		})
+	}
+}
+
+func TestValidateContactSpam(t *testing.T) {
+	negative := -1
+	cases := []struct {
+		name string
+		spam Spam
+		want string
+	}{
+		{"valid", Spam{Honeypot: "company", MinSeconds: 3, RateLimit: RateLimit{PerMinute: 2}, Blocklist: []string{" Casino ", ""}}, ""},
+		{"honeypot is a field", Spam{Honeypot: "email"}, "must not be a form field"},
+		{"honeypot name", Spam{Honeypot: "a b"}, "not a valid field name"},
+		{"min seconds", Spam{MinSeconds: -1}, "min_seconds"},
+		{"rate limit", Spam{RateLimit: RateLimit{PerMinute: -1}}, "rate_limit"},
+		{"max links", Spam{MaxLinks: &negative}, "max_links"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Site:   Site{BaseURL: "http://localhost:8080"},
+				Routes: []Route{{Path: "/", Page: "home.html"}},
+				Contact: Contact{
+					Backend: BackendWebhook,
+					Webhook: Webhook{URL: "https://hooks.example.com"},
+					Spam:    tc.spam,
+				},
+			}
+
+			err := cfg.Validate(func(string) bool { return true })
+			if tc.want != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.want) {
+					t.Fatalf("expected %q error, got %v", tc.want, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected validation error: %v", err)
+			}
+			spam := cfg.Contact.Spam
+			if spam.RateLimit.Burst != 1 || len(spam.Blocklist) != 1 || spam.Blocklist[0] != "casino" {
+				t.Fatalf("spam settings not normalised: %+v", spam)
+			}
+		})
+	}
+}
+
+func TestValidateContactCaptcha(t *testing.T) {
+	cases := []struct {
+		name    string
+		captcha Captcha
+		want    string
+	}{
+		{"recaptcha default score", Captcha{Provider: " reCAPTCHA "}, ""},
+		{"provider", Captcha{Provider: "friendlycaptcha"}, "captcha.provider"},
+		{"verify url", Captcha{Provider: CaptchaTurnstile, VerifyURL: "localhost:9000"}, "verify_url"},
+		{"score", Captcha{Provider: CaptchaReCaptcha, MinScore: 2}, "min_score"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Site:   Site{BaseURL: "http://localhost:8080"},
+				Routes: []Route{{Path: "/", Page: "home.html"}},
+				Contact: Contact{
+					Backend: BackendWebhook,
+					Webhook: Webhook{URL: "https://hooks.example.com"},
+					Captcha: tc.captcha,
+				},
+			}
+
+			err := cfg.Validate(func(string) bool { return true })
+			if tc.want != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.want) {
+					t.Fatalf("expected %q error, got %v", tc.want, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected validation error: %v", err)
+			}
+			c := cfg.Contact.Captcha
+			if c.Provider != CaptchaReCaptcha || c.MinScore != DefaultCaptchaScore || !strings.Contains(c.Endpoint(), "google.com") {
+				t.Fatalf("captcha not normalised: %+v", c)
+			}
+		})
+	}
+}
+
+func TestValidateSecurityCSRF(t *testing.T) {
+	cfg := &Config{
+		Site:     Site{BaseURL: "http://localhost:8080"},
+		Routes:   []Route{{Path: "/", Page: "home.html"}},
+		Security: Security{CSRF: CSRF{AllowedOrigins: []string{" HTTPS://Partner.example/ "}}},
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	csrf := cfg.Security.CSRF
+	if csrf.AllowedOrigins[0] != "https://partner.example" || csrf.MaxAgeMinutes != DefaultCSRFMaxAge {
+		t.Fatalf("csrf settings not normalised: %+v", csrf)
+	}
+
+	for _, origin := range []string{"partner.example", "https://partner.example/forms", "ftp://partner.example"} {
+		cfg.Security.CSRF.AllowedOrigins = []string{origin}
+		if err := cfg.Validate(func(string) bool { return true }); err == nil || !strings.Contains(err.Error(), "allowed_origins") {
+			t.Fatalf("%s: expected allowed_origins error, got %v", origin, err)
+		}
+	}
+}
+
+func TestValidateForms(t *testing.T) {
+	data := []byte(`{
+		"site": {"base_url": "http://localhost:8080"},
+		"routes": [{"path": "/", "page": "home.html"}],
+		"contact": {"recipient": "owner@example.com", "from": "no-reply@example.com", "mailgun": {"domain": "mg.example.com"}},
+		"forms": {
+			"quote": {
+				"backend": "webhook",
+				"webhook": {"url": "https://hooks.example.com/quote"},
+				"recipient": "quotes@example.com",
+				"cc": ["owner@example.com"],
+				"subject": "Quote for {{.Fields.department}} from {{.Fields.name}}{{.Fields.missing}}",
+				"fields": [
+					{"name": "name", "required": true},
+					{"name": "email", "type": "email", "required": true},
+					{"name": "department", "type": "select", "options": ["sales", "support"]}
+				],
+				"rules": [
+					{"field": "department", "equals": "sales", "to": "sales@example.com", "cc": ["boss@example.com"]},
+					{"field": "department", "equals": "support", "cc": ["help@example.com"]}
+				]
+			},
+			"newsletter": {"path": "/subscribe", "backend": "webhook", "webhook": {"url": "https://hooks.example.com/news"}}
+		}
+	}`)
+
+	cfg, err := Parse(data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	set := cfg.FormSet()
+	if names := cfg.FormNames(); strings.Join(names, ",") != "contact,newsletter,quote" {
+		t.Fatalf("unexpected forms: %v", names)
+	}
+	if set["contact"].Path != "/contact" || set["quote"].Path != "/forms/quote" || set["newsletter"].Path != "/subscribe" {
+		t.Fatalf("unexpected paths: %+v", set)
+	}
+
+	quote := set["quote"]
+	values := map[string]string{"name": "Jane", "department": "sales"}
+	if got := quote.RenderSubject(values); got != "Quote for sales from Jane" {
+		t.Fatalf("unexpected subject %q", got)
+	}
+	if to, cc := quote.Recipients(values); to != "sales@example.com" || strings.Join(cc, ",") != "owner@example.com,boss@example.com" {
+		t.Fatalf("unexpected sales routing: %s %v", to, cc)
+	}
+	if to, cc := quote.Recipients(map[string]string{"department": "support"}); to != "quotes@example.com" || len(cc) != 2 {
+		t.Fatalf("unexpected support routing: %s %v", to, cc)
+	}
+	if to, cc := quote.Recipients(map[string]string{}); to != "quotes@example.com" || len(cc) != 1 {
+		t.Fatalf("unexpected default routing: %s %v", to, cc)
+	}
+
+	// Validation must be repeatable for config reloads.
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("second validate: %v", err)
+	}
+}
+
+func TestFillSecrets(t *testing.T) {
+	secrets := Secrets{MailgunAPIKey: "env-key", SMTPPassword: "env-pass", WebhookSecret: "env-hook", CaptchaSecret: "env-captcha"}
+
+	cfg := &Config{
+		Contact: Contact{
+			Backend: BackendWebhook,
+			Webhook: Webhook{URL: "https://hooks.example.com", Secret: "config-hook"},
+			Captcha: Captcha{Provider: "turnstile"},
+		},
+		Forms: map[string]Form{
+			"quote":      {Contact: Contact{Backend: BackendWebhook, Webhook: Webhook{URL: "https://hooks.example.com/quote", Secret: "quote-hook"}}},
+			"newsletter": {Contact: Contact{Mailgun: Mailgun{Domain: "mg.example.com"}, Captcha: Captcha{Provider: "hcaptcha", Secret: "form-captcha"}}},
+		},
+	}
+	cfg.FillSecrets(secrets)
+
+	// Values set in the configuration win, in the contact block and named
+	// forms alike; blanks are filled from the runtime secrets.
+	if cfg.Contact.Webhook.Secret != "config-hook" || cfg.Contact.Captcha.Secret != "env-captcha" || cfg.Contact.Mailgun.APIKey != "env-key" {
+		t.Fatalf("unexpected contact secrets: %+v", cfg.Contact)
+	}
+	if quote := cfg.Forms["quote"]; quote.Webhook.Secret != "quote-hook" || quote.SMTP.Password != "env-pass" || quote.Captcha.Secret != "" {
+		t.Fatalf("unexpected quote secrets: %+v", quote)
+	}
+	if news := cfg.Forms["newsletter"]; news.Mailgun.APIKey != "env-key" || news.Captcha.Secret != "form-captcha" {
+		t.Fatalf("unexpected newsletter secrets: %+v", news)
+	}
+
+	// Runtime secrets alone do not create a contact form.
+	empty := &Config{}
+	empty.FillSecrets(secrets)
+	if len(empty.FormSet()) != 0 {
+		t.Fatalf("expected no forms, got %+v", empty.FormSet())
+	}
+}
+
+func TestValidateFormsErrors(t *testing.T) {
+	webhook := Contact{Backend: BackendWebhook, Webhook: Webhook{URL: "https://hooks.example.com"}}
+
+	cases := []struct {
+		name    string
+		contact Contact
+		forms   map[string]Form
+		want    string
+	}{
+		{"name", Contact{}, map[string]Form{"Quote": {Contact: webhook}}, "lowercase"},
+		{"path", Contact{}, map[string]Form{"quote": {Contact: webhook, Path: "quote"}}, "must start with '/'"},
+		{"duplicate path", Contact{}, map[string]Form{"a": {Contact: webhook, Path: "/x"}, "b": {Contact: webhook, Path: "/x"}}, "already used"},
+		{"contact conflict", Contact{Backend: BackendWebhook, Webhook: Webhook{URL: "https://hooks.example.com"}}, map[string]Form{"contact": {Contact: webhook}}, "conflicts"},
+		{"contact path", Contact{Backend: BackendWebhook, Webhook: Webhook{URL: "https://hooks.example.com"}}, map[string]Form{"other": {Contact: webhook, Path: "/contact"}}, "already used"},
+		{"backend", Contact{}, map[string]Form{"quote": {}}, "forms.quote configuration is incomplete"},
+		{"rule field", Contact{}, map[string]Form{"quote": {Contact: webhook, Rules: []FormRule{{Field: "department", Equals: "x", To: "a@example.com"}}}}, "not part of the form"},
+		{"rule target", Contact{}, map[string]Form{"quote": {Contact: webhook, Rules: []FormRule{{Field: "name", Equals: "x"}}}}, "set to, cc or both"},
+		{"rule email", Contact{}, map[string]Form{"quote": {Contact: webhook, Rules: []FormRule{{Field: "name", Equals: "x", To: "sales"}}}}, "rules[0].to"},
+		{"subject", Contact{}, map[string]Form{"quote": {Contact: Contact{Backend: BackendWebhook, Webhook: webhook.Webhook, Subject: "{{.Fields"}}}, "forms.quote.subject"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Site:    Site{BaseURL: "http://localhost:8080"},
+				Routes:  []Route{{Path: "/", Page: "home.html"}},
+				Contact: tc.contact,
+				Forms:   tc.forms,
+			}
+			err := cfg.Validate(func(string) bool { return true })
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected %q error, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateRouteSEO(t *testing.T) {
+	cases := []struct {
+		name  string
+		route Route
+		want  string
+	}{
+		{"valid", Route{Canonical: "/", OGImage: "/static/og.png", Lang: "it-IT", Alternates: map[string]string{"en": "https://example.com/en", "x-default": "/"}}, ""},
+		{"canonical", Route{Canonical: "example.com/page"}, "canonical"},
+		{"og image outside static", Route{OGImage: "/img/og.png"}, "og_image"},
+		{"lang", Route{Lang: "italian language"}, "lang"},
+		{"hreflang", Route{Alternates: map[string]string{"en_US": "/en"}}, "hreflang"},
+		{"alternate target", Route{Alternates: map[string]string{"en": "javascript:alert(1)"}}, "alternate en"},
+		{"sitemap fields", Route{ChangeFreq: "Weekly", Priority: floatPtr(0.5)}, ""},
+		{"changefreq", Route{ChangeFreq: "fortnightly"}, "changefreq"},
+		{"priority", Route{Priority: floatPtr(1.5)}, "priority"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			route := tc.route
+			route.Path, route.Page = "/", "home.html"
+			cfg := &Config{
+				Site:   Site{BaseURL: "http://localhost:8080"},
+				Routes: []Route{route},
+			}
+
+			err := cfg.Validate(func(string) bool { return true })
+			if tc.want == "" {
+				if err != nil {
+					t.Fatalf("expected valid route, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected %q error, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestValidateTrustedProxies(t *testing.T) {
+	cfg := &Config{
+		Site:     Site{BaseURL: "http://localhost:8080"},
+		Routes:   []Route{{Path: "/", Page: "home.html"}},
+		Security: Security{TrustedProxies: []string{" Fly ", "10.1.0.0/16", "192.0.2.1"}},
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if !cfg.Security.TrustsFly() {
+		t.Fatalf("fly preset not recognised: %v", cfg.Security.TrustedProxies)
+	}
+	prefixes := cfg.Security.TrustedPrefixes()
+	if len(prefixes) != len(TrustedProxyPresets["fly"])+2 || prefixes[len(prefixes)-1].String() != "192.0.2.1/32" {
+		t.Fatalf("unexpected prefixes: %v", prefixes)
+	}
+
+	for _, entry := range []string{"10.0.0.0/33", "proxy.example", "aws"} {
+		cfg.Security.TrustedProxies = []string{entry}
+		if err := cfg.Validate(func(string) bool { return true }); err == nil || !strings.Contains(err.Error(), "trusted_proxies") {
+			t.Fatalf("%s: expected trusted_proxies error, got %v", entry, err)
+		}
+	}
+}
+
+func TestValidateCollectionRoutes(t *testing.T) {
+	cfg := &Config{
+		Site:   Site{BaseURL: "http://localhost:8080"},
+		Routes: []Route{{Path: "/work/{slug}", Page: "case.html", Collection: " work "}},
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if route := cfg.Routes[0]; route.Collection != "work" || route.EntryPath("acme") != "/work/acme" {
+		t.Fatalf("unexpected collection route: %+v", route)
+	}
+
+	cases := []Route{
+		{Path: "/work/{slug}", Page: "case.html"},
+		{Path: "/work", Page: "case.html", Collection: "work"},
+		{Path: "/work/{slug}/{slug}", Page: "case.html", Collection: "work"},
+		{Path: "/work/case-{slug}", Page: "case.html", Collection: "work"},
+		{Path: "/work/{slug}", Page: "case.html", Collection: "../work"},
+		{Path: "/work/{slug}", Page: "case.html", Collection: "work", Canonical: "/work"},
+		{Path: "/work/{slug}", Page: "case.md", Collection: "work"},
+	}
+	for _, route := range cases {
+		cfg.Routes = []Route{route}
+		if err := cfg.Validate(func(string) bool { return true }); err == nil {
+			t.Fatalf("expected error for %+v", route)
+		}
+	}
+}
+
+func TestValidateSecurityHeadersAndCSP(t *testing.T) {
+	cfg := &Config{
+		Site:   Site{BaseURL: "http://localhost:8080"},
+		Routes: []Route{{Path: "/", Page: "home.html"}},
+		Security: Security{
+			Headers: SecurityHeaders{Enabled: true, FrameOptions: "sameorigin", ReferrerPolicy: "off"},
+			CSP: CSP{Enabled: true, ReportOnly: true, Directives: map[string]string{
+				" Script-Src ":    "'self'  'nonce'\thttps://cdn.example",
+				"frame-ancestors": "",
+			}},
+		},
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	headers := cfg.Security.Headers.Directives(false)
+	if headers["X-Frame-Options"] != "SAMEORIGIN" || headers["Permissions-Policy"] != DefaultPermissionsPolicy {
+		t.Fatalf("unexpected headers: %v", headers)
+	}
+	if _, ok := headers["Referrer-Policy"]; ok {
+		t.Fatalf("referrer policy should be off: %v", headers)
+	}
+	if _, ok := headers["Strict-Transport-Security"]; ok {
+		t.Fatalf("HSTS must not be sent without https: %v", headers)
+	}
+	if hsts := cfg.Security.Headers.Directives(true)["Strict-Transport-Security"]; hsts != "max-age=31536000" {
+		t.Fatalf("unexpected HSTS %q", hsts)
+	}
+
+	csp := cfg.Security.CSP
+	policy := csp.Policy("abc")
+	if !strings.HasPrefix(policy, "default-src 'self'; ") ||
+		!strings.Contains(policy, "script-src 'self' 'nonce-abc' https://cdn.example") ||
+		!strings.Contains(policy, "report-uri /csp-report; report-to csp") ||
+		strings.Contains(policy, "frame-ancestors") {
+		t.Fatalf("unexpected policy %q", policy)
+	}
+	if csp.HeaderName() != "Content-Security-Policy-Report-Only" {
+		t.Fatalf("unexpected header %q", csp.HeaderName())
+	}
+
+	for name, sec := range map[string]Security{
+		"frame_options": {Headers: SecurityHeaders{FrameOptions: "ALLOW-FROM x"}},
+		"hsts_max_age":  {Headers: SecurityHeaders{HSTSMaxAge: -5}},
+		"report_uri":    {CSP: CSP{ReportURI: "javascript:alert(1)"}},
+		"directives":    {CSP: CSP{Directives: map[string]string{"script-src": "'self'; img-src *"}}},
+	} {
+		cfg.Security = sec
+		if err := cfg.Validate(func(string) bool { return true }); err == nil || !strings.Contains(err.Error(), name) {
+			t.Fatalf("%s: expected error, got %v", name, err)
+		}
+	}
+}
+
+func TestHeaderDirectivesPatterns(t *testing.T) {
+	cfg := &Config{
+		Site:   Site{BaseURL: "http://localhost:8080"},
+		Routes: []Route{{Path: "/", Page: "home.html"}},
+		Headers: map[string]map[string]string{
+			"/**":              {"x-frame-options": "DENY", "X-Scope": "all"},
+			"/static/**":       {"Cache-Control": "public, max-age=60", "X-Scope": "static"},
+			"/static/fonts/*":  {"X-Scope": "fonts"},
+			"*.woff2":          {"Access-Control-Allow-Origin": "*"},
+			"/static/embed/":   {"-X-Frame-Options": ""},
+			"/static/embed/**": {"X-Scope": "embed"},
+		},
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	cases := map[string]map[string]string{
+		"/about": {"X-Frame-Options": "DENY", "X-Scope": "all"},
+		"/static/app.css": {
+			"X-Frame-Options": "DENY", "X-Scope": "static", "Cache-Control": "public, max-age=60",
+		},
+		"/static/fonts/inter.woff2": {
+			"X-Frame-Options": "DENY", "X-Scope": "fonts", "Cache-Control": "public, max-age=60", "Access-Control-Allow-Origin": "*",
+		},
+		"/static/fonts/sub/inter.woff2": {
+			"X-Frame-Options": "DENY", "X-Scope": "static", "Cache-Control": "public, max-age=60", "Access-Control-Allow-Origin": "*",
+		},
+		"/static/embed": {
+			"-X-Frame-Options": "", "X-Scope": "embed", "Cache-Control": "public, max-age=60",
+		},
+	}
+	for path, want := range cases {
+		got := cfg.HeaderDirectives(path)
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %v, want %v", path, got, want)
+		}
+		for key, val := range want {
+			if v, ok := got[key]; !ok || v != val {
+				t.Fatalf("%s: got %v, want %v", path, got, want)
+			}
+		}
+	}
+
+	cfg.Headers = map[string]map[string]string{"/a/***": {"X": "y"}}
+	if err := cfg.Validate(func(string) bool { return true }); err == nil || !strings.Contains(err.Error(), "headers") {
+		t.Fatalf("expected pattern error, got %v", err)
+	}
+}
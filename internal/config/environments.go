@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// Environment overrides crawler-facing behaviour for a deployment such as
+// staging or preview.
+type Environment struct {
+	// RobotsPolicy replaces site.robots_policy while the environment is active.
+	RobotsPolicy string `json:"robots_policy"`
+	// NoIndex serves a disallow-all robots.txt (unless RobotsPolicy is set)
+	// and adds X-Robots-Tag: noindex to every response.
+	NoIndex bool `json:"noindex"`
+	// Hosts activates the environment for matching request hosts regardless
+	// of site.environment. A leading "*." matches any subdomain.
+	Hosts []string `json:"hosts"`
+}
+
+// DisallowAllPolicy is served by environments marked noindex.
+const DisallowAllPolicy = "User-agent: *\nDisallow: /"
+
+// EnvironmentFor returns the environment that applies to a request for host:
+// the first environment (by name) listing a matching host, otherwise the one
+// named by site.environment. The returned name is empty when none applies.
+func (s Site) EnvironmentFor(host string) (string, Environment) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	if host != "" {
+		for _, name := range s.environmentNames() {
+			env := s.Environments[name]
+			for _, pattern := range env.Hosts {
+				if hostMatches(pattern, host) {
+					return name, env
+				}
+			}
+		}
+	}
+
+	if env, ok := s.Environments[s.Environment]; ok {
+		return s.Environment, env
+	}
+
+	return "", Environment{}
+}
+
+// RobotsPolicyFor returns the robots.txt policy for the named environment,
+// falling back to site.robots_policy.
+func (s Site) RobotsPolicyFor(name string) string {
+	env, ok := s.Environments[name]
+	switch {
+	case ok && strings.TrimSpace(env.RobotsPolicy) != "":
+		return env.RobotsPolicy
+	case ok && env.NoIndex:
+		return DisallowAllPolicy
+	default:
+		return s.RobotsPolicy
+	}
+}
+
+func (s Site) environmentNames() []string {
+	names := make([]string, 0, len(s.Environments))
+	for name := range s.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func hostMatches(pattern, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return host == pattern
+}
+
+func (c *Config) validateEnvironments() error {
+	c.Site.Environment = strings.TrimSpace(c.Site.Environment)
+
+	for name, env := range c.Site.Environments {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("site.environments: name is required")
+		}
+		for i, pattern := range env.Hosts {
+			pattern = strings.ToLower(strings.TrimSpace(pattern))
+			if pattern == "" || strings.ContainsAny(pattern, "/: ") || strings.Contains(strings.TrimPrefix(pattern, "*."), "*") {
+				return fmt.Errorf("site.environments.%s: invalid host %q", name, env.Hosts[i])
+			}
+			env.Hosts[i] = pattern
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvironmentFor(t *testing.T) {
+	site := Site{
+		RobotsPolicy: "User-agent: *\nAllow: /",
+		Environment:  "production",
+		Environments: map[string]Environment{
+			"production": {},
+			"preview":    {NoIndex: true, Hosts: []string{"*.fly.dev"}},
+			"staging":    {RobotsPolicy: "User-agent: *\nDisallow: /private", Hosts: []string{"staging.example.com"}},
+		},
+	}
+
+	cases := []struct {
+		host   string
+		name   string
+		policy string
+	}{
+		{"www.example.com", "production", site.RobotsPolicy},
+		{"landing-pr-12.fly.dev:443", "preview", DisallowAllPolicy},
+		{"STAGING.example.com.", "staging", "User-agent: *\nDisallow: /private"},
+		{"fly.dev", "production", site.RobotsPolicy},
+	}
+
+	for _, tc := range cases {
+		name, _ := site.EnvironmentFor(tc.host)
+		if name != tc.name {
+			t.Fatalf("EnvironmentFor(%q) = %q, want %q", tc.host, name, tc.name)
+		}
+		if got := site.RobotsPolicyFor(name); got != tc.policy {
+			t.Fatalf("RobotsPolicyFor(%q) = %q, want %q", name, got, tc.policy)
+		}
+	}
+
+	site.Environment = "unknown"
+	if name, env := site.EnvironmentFor("www.example.com"); name != "" || env.NoIndex {
+		t.Fatalf("unknown environment should fall back to site defaults, got %q", name)
+	}
+}
+
+func TestValidateEnvironmentHosts(t *testing.T) {
+	cfg := &Config{
+		Site: Site{
+			BaseURL:      "http://localhost:8080",
+			Environments: map[string]Environment{"staging": {Hosts: []string{"https://staging.example.com"}}},
+		},
+		Routes: []Route{{Path: "/", Page: "home.html"}},
+	}
+
+	err := cfg.Validate(func(string) bool { return true })
+	if err == nil || !strings.Contains(err.Error(), "invalid host") {
+		t.Fatalf("expected invalid host error, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Contact form field types.
+const (
+	FieldText     = "text"
+	FieldTextarea = "textarea"
+	FieldEmail    = "email"
+	FieldTel      = "tel"
+	FieldURL      = "url"
+	FieldNumber   = "number"
+	FieldSelect   = "select"
+	FieldCheckbox = "checkbox"
+)
+
+// Field declares one input of the contact form.
+type Field struct {
+	// Name is the form parameter name.
+	Name string `json:"name"`
+	// Label is used in error messages and the delivered message; it
+	// defaults to Name.
+	Label string `json:"label"`
+	// Type is one of text (default), textarea, email, tel, url, number,
+	// select or checkbox.
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	// MinLength and MaxLength bound the value length in characters.
+	MinLength int `json:"min_length"`
+	MaxLength int `json:"max_length"`
+	// Pattern is a regular expression the whole value must match.
+	Pattern string `json:"pattern"`
+	// Options lists the allowed values. It is required for select fields.
+	Options []string `json:"options"`
+
+	pattern *regexp.Regexp
+}
+
+// DefaultFields is the schema used when contact.fields is empty.
+var DefaultFields = []Field{
+	{Name: "name", Label: "Name", Type: FieldText, Required: true, MaxLength: 200},
+	{Name: "email", Label: "Email", Type: FieldEmail, Required: true, MaxLength: 320},
+	{Name: "message", Label: "Message", Type: FieldTextarea, Required: true, MaxLength: 10000},
+}
+
+var fieldNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// FormFields returns the declared fields, or DefaultFields when none are
+// configured.
+func (c Contact) FormFields() []Field {
+	if len(c.Fields) == 0 {
+		return DefaultFields
+	}
+	return c.Fields
+}
+
+// DisplayLabel returns the label, falling back to the field name.
+func (f Field) DisplayLabel() string {
+	if f.Label != "" {
+		return f.Label
+	}
+	return f.Name
+}
+
+// Regexp returns the compiled pattern, anchored to the whole value, or nil
+// when the field has none.
+func (f Field) Regexp() *regexp.Regexp {
+	if f.pattern != nil || f.Pattern == "" {
+		return f.pattern
+	}
+	re, err := compileFieldPattern(f.Pattern)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+func validateFields(prefix string, fields []Field) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]struct{}, len(fields))
+	hasEmail := false
+
+	for i := range fields {
+		f := &fields[i]
+		f.Name = strings.TrimSpace(f.Name)
+		f.Label = strings.TrimSpace(f.Label)
+		f.Type = strings.ToLower(strings.TrimSpace(f.Type))
+
+		if f.Name == "" {
+			return fmt.Errorf("%s.fields[%d]: name is required", prefix, i)
+		}
+		if !fieldNamePattern.MatchString(f.Name) {
+			return fmt.Errorf("%s field %s: name must start with a letter and contain only letters, digits, '-' or '_'", prefix, f.Name)
+		}
+		if _, ok := seen[f.Name]; ok {
+			return fmt.Errorf("%s: duplicate field %q", prefix, f.Name)
+		}
+		seen[f.Name] = struct{}{}
+
+		switch f.Type {
+		case "":
+			f.Type = FieldText
+		case FieldText, FieldTextarea, FieldEmail, FieldTel, FieldURL, FieldNumber, FieldSelect, FieldCheckbox:
+		default:
+			return fmt.Errorf("%s field %s: unsupported type %q", prefix, f.Name, f.Type)
+		}
+
+		if f.MinLength < 0 || f.MaxLength < 0 {
+			return fmt.Errorf("%s field %s: lengths must not be negative", prefix, f.Name)
+		}
+		if f.MaxLength > 0 && f.MinLength > f.MaxLength {
+			return fmt.Errorf("%s field %s: min_length exceeds max_length", prefix, f.Name)
+		}
+
+		if f.Pattern != "" {
+			re, err := compileFieldPattern(f.Pattern)
+			if err != nil {
+				return fmt.Errorf("%s field %s: pattern: %w", prefix, f.Name, err)
+			}
+			f.pattern = re
+		}
+
+		if f.Type == FieldSelect && len(f.Options) == 0 {
+			return fmt.Errorf("%s field %s: select fields require options", prefix, f.Name)
+		}
+
+		if f.Type == FieldEmail && f.Required {
+			hasEmail = true
+		}
+	}
+
+	if !hasEmail {
+		return fmt.Errorf("%s.fields must include a required email field for replies", prefix)
+	}
+
+	return nil
+}
+
+func compileFieldPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
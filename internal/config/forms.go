@@ -0,0 +1,262 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// ContactForm names the form built from the legacy top-level contact block.
+const ContactForm = "contact"
+
+// Form is a named form with its own endpoint, schema and delivery settings.
+// The embedded Contact fields (backend, recipient, from, subject, fields and
+// backend credentials) appear directly in the form's JSON object.
+type Form struct {
+	Contact
+	// Name is the key of the form in config.forms.
+	Name string `json:"-"`
+	// Path is the POST endpoint; it defaults to /forms/<name>.
+	Path string `json:"path"`
+	// Rules route a submission by field value. The first matching rule
+	// wins.
+	Rules []FormRule `json:"rules"`
+
+	subject *template.Template
+}
+
+// FormRule redirects a submission when Field equals Equals: To replaces the
+// recipient when set and CC is added to the copy list.
+type FormRule struct {
+	Field  string   `json:"field"`
+	Equals string   `json:"equals"`
+	To     string   `json:"to"`
+	CC     []string `json:"cc"`
+}
+
+// SubjectData is passed to form subject templates.
+type SubjectData struct {
+	Form   string
+	Fields map[string]string
+}
+
+var formNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// FormSet returns every configured form keyed by name. A non-empty legacy
+// contact block is included as the "contact" form served at /contact.
+func (c *Config) FormSet() map[string]Form {
+	set := make(map[string]Form, len(c.Forms)+1)
+	for name, f := range c.Forms {
+		f.Name = name
+		set[name] = f
+	}
+	if !c.Contact.isZero() {
+		set[ContactForm] = c.contactForm()
+	}
+	return set
+}
+
+// FormNames returns the form names in sorted order.
+func (c *Config) FormNames() []string {
+	set := c.FormSet()
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Secrets are delivery and CAPTCHA secrets supplied at runtime, usually from
+// environment variables, so they can stay out of the configuration file.
+type Secrets struct {
+	MailgunAPIKey string
+	SMTPPassword  string
+	WebhookSecret string
+	CaptchaSecret string
+}
+
+// FillSecrets copies s into the contact block and every named form wherever
+// the configuration leaves the secret blank. A secret set in the
+// configuration always wins. An empty contact block stays empty.
+func (c *Config) FillSecrets(s Secrets) {
+	if !c.Contact.isZero() {
+		c.Contact.fillSecrets(s)
+	}
+	for name, form := range c.Forms {
+		form.fillSecrets(s)
+		c.Forms[name] = form
+	}
+}
+
+func (c *Contact) fillSecrets(s Secrets) {
+	if c.Mailgun.APIKey == "" {
+		c.Mailgun.APIKey = s.MailgunAPIKey
+	}
+	if c.SMTP.Password == "" {
+		c.SMTP.Password = s.SMTPPassword
+	}
+	if c.Webhook.Secret == "" {
+		c.Webhook.Secret = s.WebhookSecret
+	}
+	if c.Captcha.Secret == "" && c.Captcha.Provider != "" {
+		c.Captcha.Secret = s.CaptchaSecret
+	}
+}
+
+func (c *Config) contactForm() Form {
+	return Form{Contact: c.Contact, Name: ContactForm, Path: "/contact"}
+}
+
+// Recipients returns the recipient and copy list for a submission with the
+// given field values, applying the first matching rule.
+func (f Form) Recipients(values map[string]string) (string, []string) {
+	to := f.Recipient
+	cc := append([]string(nil), f.CC...)
+
+	for _, rule := range f.Rules {
+		if values[rule.Field] != rule.Equals {
+			continue
+		}
+		if rule.To != "" {
+			to = rule.To
+		}
+		cc = append(cc, rule.CC...)
+		break
+	}
+
+	return to, cc
+}
+
+// RenderSubject expands the subject template with the submitted values. It
+// returns the subject verbatim when it is not a template, and "" when it is
+// empty or fails to render.
+func (f Form) RenderSubject(values map[string]string) string {
+	tmpl := f.subject
+	if tmpl == nil {
+		var err error
+		if tmpl, err = parseSubject(f.Name, f.Subject); err != nil {
+			return ""
+		}
+		if tmpl == nil {
+			return f.Subject
+		}
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, SubjectData{Form: f.Name, Fields: values}); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func (c *Config) validateForms() error {
+	paths := make(map[string]string, len(c.Forms)+1)
+
+	if !c.Contact.isZero() {
+		if _, ok := c.Forms[ContactForm]; ok {
+			return fmt.Errorf("forms.%s conflicts with the top-level contact block; configure one or the other", ContactForm)
+		}
+		form := c.contactForm()
+		if err := form.validateRules(ContactForm); err != nil {
+			return err
+		}
+		paths[form.Path] = ContactForm
+	}
+
+	names := make([]string, 0, len(c.Forms))
+	for name := range c.Forms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		form := c.Forms[name]
+		prefix := "forms." + name
+
+		if !formNamePattern.MatchString(name) {
+			return fmt.Errorf("%s: name must contain only lowercase letters, digits, '-' or '_'", prefix)
+		}
+
+		form.Contact.normalize()
+		form.Name = name
+		form.Path = strings.TrimSpace(form.Path)
+		if form.Path == "" {
+			form.Path = "/forms/" + name
+		}
+		if !strings.HasPrefix(form.Path, "/") {
+			return fmt.Errorf("%s.path must start with '/'", prefix)
+		}
+		form.Path = cleanPath(form.Path)
+		if other, ok := paths[form.Path]; ok {
+			return fmt.Errorf("%s.path %q is already used by form %q", prefix, form.Path, other)
+		}
+		paths[form.Path] = name
+
+		if err := form.Contact.validate(prefix); err != nil {
+			return err
+		}
+		if err := form.validateRules(prefix); err != nil {
+			return err
+		}
+
+		c.Forms[name] = form
+	}
+
+	return nil
+}
+
+func (f *Form) validateRules(prefix string) error {
+	for _, addr := range f.CC {
+		if !strings.Contains(addr, "@") {
+			return fmt.Errorf("%s.cc: %q is not a valid email address", prefix, addr)
+		}
+	}
+
+	if f.Subject != "" {
+		tmpl, err := parseSubject(f.Name, f.Subject)
+		if err != nil {
+			return fmt.Errorf("%s.subject: %w", prefix, err)
+		}
+		f.subject = tmpl
+	}
+
+	fields := make(map[string]struct{})
+	for _, field := range f.FormFields() {
+		fields[field.Name] = struct{}{}
+	}
+
+	for i := range f.Rules {
+		rule := &f.Rules[i]
+		rule.Field = strings.TrimSpace(rule.Field)
+		rule.To = strings.TrimSpace(rule.To)
+
+		if _, ok := fields[rule.Field]; !ok {
+			return fmt.Errorf("%s.rules[%d]: field %q is not part of the form", prefix, i, rule.Field)
+		}
+		if rule.To == "" && len(rule.CC) == 0 {
+			return fmt.Errorf("%s.rules[%d]: set to, cc or both", prefix, i)
+		}
+		if rule.To != "" && !strings.Contains(rule.To, "@") {
+			return fmt.Errorf("%s.rules[%d].to must be a valid email address", prefix, i)
+		}
+		for _, addr := range rule.CC {
+			if !strings.Contains(addr, "@") {
+				return fmt.Errorf("%s.rules[%d].cc: %q is not a valid email address", prefix, i, addr)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseSubject compiles subject as a text/template when it contains an
+// action. Plain subjects return a nil template.
+func parseSubject(name, subject string) (*template.Template, error) {
+	if !strings.Contains(subject, "{{") {
+		return nil, nil
+	}
+	return template.New(name).Option("missingkey=zero").Parse(subject)
+}
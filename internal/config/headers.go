@@ -0,0 +1,169 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// headerRule is a compiled entry of Config.Headers.
+type headerRule struct {
+	pattern string
+	// base rules have no '/' and match the last path segment.
+	base        bool
+	re          *regexp.Regexp
+	specificity int
+	headers     map[string]string
+}
+
+// HeaderDirectives returns the configured headers for a request path.
+//
+// Keys of Config.Headers are exact paths ("/about"), globs where '*' matches
+// within one segment and '**' across segments ("/static/**"), or globs
+// without a '/' that match the last segment anywhere ("*.woff2"). Every
+// matching rule applies; when two set the same header the more specific one
+// wins: exact paths first, then the pattern with more literal characters.
+//
+// A header named with a leading '-' is removed instead of set; it appears
+// in the result with its '-' so callers can delete it.
+func (c *Config) HeaderDirectives(p string) map[string]string {
+	if c == nil || len(c.Headers) == 0 {
+		return nil
+	}
+
+	rules := c.headerRules
+	if rules == nil {
+		var err error
+		if rules, err = compileHeaderRules(c.Headers); err != nil {
+			return nil
+		}
+	}
+
+	p = cleanPath(p)
+	base := path.Base(p)
+
+	var out map[string]string
+	for _, rule := range rules {
+		subject := p
+		if rule.base {
+			subject = base
+		}
+		if !rule.re.MatchString(subject) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		for key, val := range rule.headers {
+			name := strings.TrimPrefix(key, "-")
+			delete(out, name)
+			delete(out, "-"+name)
+			out[key] = val
+		}
+	}
+
+	return out
+}
+
+func (c *Config) validateHeaders() error {
+	normalized := make(map[string]map[string]string, len(c.Headers))
+	for pattern, hdrs := range c.Headers {
+		clean := make(map[string]string, len(hdrs))
+		for key, val := range hdrs {
+			key = canonicalHeaderKey(key)
+			if strings.TrimPrefix(key, "-") == "" {
+				return fmt.Errorf("headers %q: empty header name", pattern)
+			}
+			clean[key] = strings.TrimSpace(val)
+		}
+		normalized[cleanHeaderPattern(pattern)] = clean
+	}
+
+	rules, err := compileHeaderRules(normalized)
+	if err != nil {
+		return err
+	}
+
+	c.Headers = normalized
+	c.headerRules = rules
+	return nil
+}
+
+// compileHeaderRules compiles the patterns ordered from least to most
+// specific, so later rules override earlier ones.
+func compileHeaderRules(headers map[string]map[string]string) ([]headerRule, error) {
+	rules := make([]headerRule, 0, len(headers))
+	for pattern, hdrs := range headers {
+		if pattern == "" {
+			return nil, errors.New("headers: empty path pattern")
+		}
+		if strings.Contains(pattern, "***") {
+			return nil, fmt.Errorf("headers %q: use '*' or '**'", pattern)
+		}
+
+		rule := headerRule{
+			pattern: pattern,
+			base:    !strings.Contains(pattern, "/"),
+			headers: hdrs,
+		}
+		if strings.Contains(pattern, "*") {
+			rule.specificity = len(strings.ReplaceAll(pattern, "*", ""))
+		} else {
+			rule.specificity = 1 << 30
+		}
+		rule.re = regexp.MustCompile(globRegexp(pattern))
+		rules = append(rules, rule)
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].specificity != rules[j].specificity {
+			return rules[i].specificity < rules[j].specificity
+		}
+		return rules[i].pattern < rules[j].pattern
+	})
+
+	return rules, nil
+}
+
+// globRegexp translates a header pattern to an anchored regular expression.
+// A trailing "/**" also matches the directory itself.
+func globRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+
+	rest := pattern
+	suffix := ""
+	if strings.HasSuffix(rest, "/**") {
+		rest = strings.TrimSuffix(rest, "/**")
+		suffix = "(?:/.*)?"
+	}
+
+	for i := 0; i < len(rest); i++ {
+		switch {
+		case strings.HasPrefix(rest[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case rest[i] == '*':
+			b.WriteString("[^/]*")
+		default:
+			b.WriteString(regexp.QuoteMeta(rest[i : i+1]))
+		}
+	}
+
+	b.WriteString(suffix)
+	b.WriteString("$")
+	return b.String()
+}
+
+// cleanHeaderPattern normalises a Config.Headers key. Segment patterns such
+// as "*.woff2" are kept as they are; anything else is cleaned as a path.
+func cleanHeaderPattern(pattern string) string {
+	pattern = strings.TrimSpace(pattern)
+	if strings.Contains(pattern, "*") && !strings.Contains(pattern, "/") {
+		return pattern
+	}
+	return cleanPath(pattern)
+}
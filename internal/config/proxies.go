@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxyPresets expands the named entries accepted in
+// security.trusted_proxies.
+var TrustedProxyPresets = map[string][]string{
+	"loopback": {"127.0.0.0/8", "::1/128"},
+	"private":  {"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"},
+	// fly covers the Fly.io edge proxies, which reach machines over the
+	// private network and set Fly-Client-IP.
+	"fly": {"172.16.0.0/12", "fdaa::/16"},
+	// cloudflare lists the published Cloudflare edge ranges.
+	"cloudflare": {
+		"173.245.48.0/20", "103.21.244.0/22", "103.22.200.0/22", "103.31.4.0/22",
+		"141.101.64.0/18", "108.162.192.0/18", "190.93.240.0/20", "188.114.96.0/20",
+		"197.234.240.0/22", "198.41.128.0/17", "162.158.0.0/15", "104.16.0.0/13",
+		"104.24.0.0/14", "172.64.0.0/13", "131.0.72.0/22",
+		"2400:cb00::/32", "2606:4700::/32", "2803:f800::/32", "2405:b500::/32",
+		"2405:8100::/32", "2a06:98c0::/29", "2c0f:f248::/32",
+	},
+}
+
+// TrustedPrefixes expands security.trusted_proxies into address prefixes.
+// Entries are validated by Validate; invalid ones are skipped here.
+func (s Security) TrustedPrefixes() []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range s.TrustedProxies {
+		expanded, err := parseTrustedProxy(entry)
+		if err != nil {
+			continue
+		}
+		prefixes = append(prefixes, expanded...)
+	}
+	return prefixes
+}
+
+// TrustsFly reports whether the fly preset is enabled, which makes the
+// Fly-Client-IP header authoritative.
+func (s Security) TrustsFly() bool {
+	for _, entry := range s.TrustedProxies {
+		if entry == "fly" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) validateTrustedProxies() error {
+	for i, entry := range c.Security.TrustedProxies {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if _, err := parseTrustedProxy(entry); err != nil {
+			return fmt.Errorf("security.trusted_proxies[%d]: %w", i, err)
+		}
+		c.Security.TrustedProxies[i] = entry
+	}
+	return nil
+}
+
+// parseTrustedProxy accepts a preset name, a CIDR prefix or a single address.
+func parseTrustedProxy(entry string) ([]netip.Prefix, error) {
+	if preset, ok := TrustedProxyPresets[entry]; ok {
+		prefixes := make([]netip.Prefix, len(preset))
+		for i, cidr := range preset {
+			prefixes[i] = netip.MustParsePrefix(cidr)
+		}
+		return prefixes, nil
+	}
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid CIDR prefix", entry)
+		}
+		return []netip.Prefix{prefix.Masked()}, nil
+	}
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not an address, CIDR prefix or preset (loopback, private, fly, cloudflare)", entry)
+	}
+	addr = addr.Unmap()
+	return []netip.Prefix{netip.PrefixFrom(addr, addr.BitLen())}, nil
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Splat is the placeholder in a redirect target that receives the part of the
+// request path matched by a wildcard source.
+const Splat = ":splat"
+
+// Redirect maps an old path to a new location.
+//
+// From is either an exact path ("/contact") or a wildcard ending in "/*"
+// ("/blog/*"), which matches the base path and everything beneath it. To is a
+// path or absolute URL and may contain :splat. Status defaults to 301.
+type Redirect struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Status int    `json:"status"`
+}
+
+// Wildcard reports whether the redirect matches a path prefix.
+func (r Redirect) Wildcard() bool {
+	return strings.HasSuffix(r.From, "/*")
+}
+
+// Base returns the source path without the wildcard suffix.
+func (r Redirect) Base() string {
+	if !r.Wildcard() {
+		return r.From
+	}
+	if base := strings.TrimSuffix(r.From, "/*"); base != "" {
+		return base
+	}
+	return "/"
+}
+
+// Target returns the destination for path, or false when the redirect does
+// not apply. The request query string is not included.
+func (r Redirect) Target(path string) (string, bool) {
+	if !r.Wildcard() {
+		if path != r.From {
+			return "", false
+		}
+		return strings.ReplaceAll(r.To, Splat, ""), true
+	}
+
+	base := r.Base()
+	var splat string
+	switch {
+	case path == base:
+	case base == "/":
+		splat = strings.TrimPrefix(path, "/")
+	case strings.HasPrefix(path, base+"/"):
+		splat = strings.TrimPrefix(path, base+"/")
+	default:
+		return "", false
+	}
+
+	return strings.ReplaceAll(r.To, Splat, splat), true
+}
+
+// MatchRedirect returns the redirect that applies to path along with its
+// target. Exact sources win over wildcards, and longer wildcards over shorter
+// ones.
+func (c *Config) MatchRedirect(path string) (Redirect, string, bool) {
+	if c == nil {
+		return Redirect{}, "", false
+	}
+
+	var (
+		best       Redirect
+		bestTarget string
+		found      bool
+	)
+
+	for _, rd := range c.Redirects {
+		target, ok := rd.Target(path)
+		if !ok {
+			continue
+		}
+		if !rd.Wildcard() {
+			return rd, target, true
+		}
+		if !found || len(rd.Base()) > len(best.Base()) {
+			best, bestTarget, found = rd, target, true
+		}
+	}
+
+	return best, bestTarget, found
+}
+
+func (c *Config) validateRedirects(routes map[string]struct{}) error {
+	seen := make(map[string]struct{}, len(c.Redirects))
+
+	for i := range c.Redirects {
+		rd := &c.Redirects[i]
+		rd.From = strings.TrimSpace(rd.From)
+		rd.To = strings.TrimSpace(rd.To)
+
+		if rd.From == "" {
+			return fmt.Errorf("redirect %d: from is required", i)
+		}
+		if !strings.HasPrefix(rd.From, "/") {
+			return fmt.Errorf("redirect %s: from must start with '/'", rd.From)
+		}
+		if strings.Contains(strings.TrimSuffix(rd.From, "/*"), "*") {
+			return fmt.Errorf("redirect %s: '*' is only allowed as a trailing /* wildcard", rd.From)
+		}
+		if !rd.Wildcard() {
+			rd.From = cleanPath(rd.From)
+		}
+
+		if _, ok := seen[rd.From]; ok {
+			return fmt.Errorf("duplicate redirect from %q", rd.From)
+		}
+		seen[rd.From] = struct{}{}
+
+		if rd.To == "" {
+			return fmt.Errorf("redirect %s: to is required", rd.From)
+		}
+		if !isPathOrHTTPURL(rd.To) {
+			return fmt.Errorf("redirect %s: to must be a path or http(s) URL", rd.From)
+		}
+		if !rd.Wildcard() && strings.Contains(rd.To, Splat) {
+			return fmt.Errorf("redirect %s: %s requires a wildcard source", rd.From, Splat)
+		}
+
+		switch rd.Status {
+		case 0:
+			rd.Status = http.StatusMovedPermanently
+		case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		default:
+			return fmt.Errorf("redirect %s: status must be 301, 302, 307 or 308", rd.From)
+		}
+
+		if _, ok := routes[rd.From]; ok {
+			return fmt.Errorf("redirect %s conflicts with a route of the same path", rd.From)
+		}
+		if route := shadowedRoute(*rd, routes); route != "" {
+			return fmt.Errorf("redirect %s conflicts with route %s", rd.From, route)
+		}
+	}
+
+	for _, rd := range c.Redirects {
+		if err := c.checkRedirectChain(rd, routes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// shadowedRoute returns the first route, in path order, at or beneath the
+// base of the wildcard rd, or "" when there is none. The root wildcard /* is
+// exempt: it covers every route and only ever serves unknown paths.
+func shadowedRoute(rd Redirect, routes map[string]struct{}) string {
+	base := rd.Base()
+	if !rd.Wildcard() || base == "/" {
+		return ""
+	}
+
+	var found []string
+	for route := range routes {
+		if route == base || strings.HasPrefix(route, base+"/") {
+			found = append(found, route)
+		}
+	}
+	if len(found) == 0 {
+		return ""
+	}
+	sort.Strings(found)
+	return found[0]
+}
+
+// checkRedirectChain follows rd through the other redirects and fails when
+// the chain revisits a path. Wildcards are probed with a sample splat.
+func (c *Config) checkRedirectChain(rd Redirect, routes map[string]struct{}) error {
+	start := rd.From
+	if rd.Wildcard() {
+		start = strings.TrimSuffix(rd.Base(), "/") + "/redirect-check"
+	}
+
+	visited := map[string]struct{}{start: {}}
+	path := start
+
+	for hops := 0; hops <= len(c.Redirects); hops++ {
+		if _, ok := routes[path]; ok {
+			return nil
+		}
+
+		_, target, ok := c.MatchRedirect(path)
+		if !ok || !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+			return nil
+		}
+
+		if idx := strings.IndexAny(target, "?#"); idx >= 0 {
+			target = target[:idx]
+		}
+		target = cleanPath(target)
+
+		if _, ok := visited[target]; ok {
+			return fmt.Errorf("redirect %s: loop detected via %s", rd.From, target)
+		}
+		visited[target] = struct{}{}
+		path = target
+	}
+
+	return fmt.Errorf("redirect %s: chain does not terminate", rd.From)
+}
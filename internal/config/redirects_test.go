@@ -0,0 +1,97 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedirectTarget(t *testing.T) {
+	cases := []struct {
+		rd     Redirect
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{Redirect{From: "/contact", To: "/contatta"}, "/contact", "/contatta", true},
+		{Redirect{From: "/contact", To: "/contatta"}, "/contact/x", "", false},
+		{Redirect{From: "/blog/*", To: "/news/:splat"}, "/blog/2024/post", "/news/2024/post", true},
+		{Redirect{From: "/blog/*", To: "/news/:splat"}, "/blog", "/news/", true},
+		{Redirect{From: "/blog/*", To: "/news/:splat"}, "/blogger", "", false},
+		{Redirect{From: "/*", To: "https://example.org/:splat"}, "/a/b", "https://example.org/a/b", true},
+	}
+
+	for _, tc := range cases {
+		got, ok := tc.rd.Target(tc.path)
+		if ok != tc.wantOK || got != tc.want {
+			t.Fatalf("%s -> Target(%q) = %q, %v; want %q, %v", tc.rd.From, tc.path, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestMatchRedirectPrecedence(t *testing.T) {
+	cfg := &Config{Redirects: []Redirect{
+		{From: "/*", To: "/"},
+		{From: "/docs/*", To: "/help/:splat"},
+		{From: "/docs/old/*", To: "/archive/:splat"},
+		{From: "/docs/old/intro", To: "/start"},
+	}}
+
+	cases := map[string]string{
+		"/docs/old/intro": "/start",
+		"/docs/old/faq":   "/archive/faq",
+		"/docs/faq":       "/help/faq",
+		"/elsewhere":      "/",
+	}
+
+	for path, want := range cases {
+		if _, got, ok := cfg.MatchRedirect(path); !ok || got != want {
+			t.Fatalf("MatchRedirect(%q) = %q, %v; want %q", path, got, ok, want)
+		}
+	}
+}
+
+func TestValidateRedirects(t *testing.T) {
+	cases := []struct {
+		name      string
+		redirects []Redirect
+		want      string
+	}{
+		{"valid", []Redirect{{From: "/contact/", To: "/contatta"}, {From: "/blog/*", To: "/news/:splat", Status: 308}, {From: "/old", To: "/contact"}}, ""},
+		{"missing to", []Redirect{{From: "/a"}}, "to is required"},
+		{"bad status", []Redirect{{From: "/a", To: "/b", Status: 303}}, "status"},
+		{"bad wildcard", []Redirect{{From: "/a*b", To: "/b"}}, "wildcard"},
+		{"splat without wildcard", []Redirect{{From: "/a", To: "/b/:splat"}}, ":splat"},
+		{"duplicate", []Redirect{{From: "/a", To: "/b"}, {From: "/a/", To: "/c"}}, "duplicate"},
+		{"route conflict", []Redirect{{From: "/about", To: "/"}}, "conflicts"},
+		{"wildcard base conflict", []Redirect{{From: "/about/*", To: "/"}}, "conflicts with route /about"},
+		{"wildcard child conflict", []Redirect{{From: "/team/*", To: "/about"}}, "conflicts with route /team/lead"},
+		{"loop", []Redirect{{From: "/a", To: "/b"}, {From: "/b", To: "/c?x=1"}, {From: "/c", To: "/a"}}, "loop"},
+		{"self loop", []Redirect{{From: "/a", To: "/a/"}}, "loop"},
+		{"wildcard loop", []Redirect{{From: "/x/*", To: "/y/:splat"}, {From: "/y/*", To: "/x/:splat"}}, "loop"},
+		{"unbounded", []Redirect{{From: "/*", To: "/new/:splat"}}, "does not terminate"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Site:      Site{BaseURL: "http://localhost:8080"},
+				Routes:    []Route{{Path: "/", Page: "home.html"}, {Path: "/about", Page: "about.html"}, {Path: "/contatta", Page: "contact.html"}, {Path: "/team/lead", Page: "about.html"}},
+				Redirects: tc.redirects,
+			}
+
+			err := cfg.Validate(func(string) bool { return true })
+			if tc.want == "" {
+				if err != nil {
+					t.Fatalf("expected valid redirects, got %v", err)
+				}
+				if cfg.Redirects[0].From != "/contact" || cfg.Redirects[0].Status != 301 {
+					t.Fatalf("redirect not normalized: %+v", cfg.Redirects[0])
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected %q error, got %v", tc.want, err)
+			}
+		})
+	}
+}
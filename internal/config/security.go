@@ -0,0 +1,284 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultCSRFMaxAge is the CSRF token lifetime, in minutes, used when none
+// is configured.
+const DefaultCSRFMaxAge = 12 * 60
+
+// Security header defaults.
+const (
+	DefaultHSTSMaxAge        = 365 * 24 * 60 * 60
+	DefaultReferrerPolicy    = "strict-origin-when-cross-origin"
+	DefaultPermissionsPolicy = "camera=(), microphone=(), geolocation=()"
+	DefaultFrameOptions      = "DENY"
+)
+
+// HeaderOff disables an individual security header or the CSP report URI.
+const HeaderOff = "off"
+
+// CSPNonce in a CSP directive is replaced with the per-request nonce.
+const CSPNonce = "'nonce'"
+
+// DefaultCSPReportURI is the built-in endpoint that logs CSP violations.
+const DefaultCSPReportURI = "/csp-report"
+
+// CSPReportGroup names the Reporting-Endpoints entry used by report-to.
+const CSPReportGroup = "csp"
+
+// DefaultCSPDirectives is the policy used for directives the configuration
+// does not set.
+var DefaultCSPDirectives = map[string]string{
+	"default-src":     "'self'",
+	"script-src":      "'self' " + CSPNonce,
+	"style-src":       "'self' 'unsafe-inline'",
+	"img-src":         "'self' data:",
+	"font-src":        "'self'",
+	"connect-src":     "'self'",
+	"object-src":      "'none'",
+	"base-uri":        "'self'",
+	"form-action":     "'self'",
+	"frame-ancestors": "'none'",
+}
+
+// Security groups request-hardening settings.
+type Security struct {
+	CSRF CSRF `json:"csrf"`
+	// TrustedProxies lists the CIDR prefixes, addresses or presets
+	// (loopback, private, fly, cloudflare) whose forwarding headers are
+	// believed when resolving the client address. Empty trusts no one.
+	TrustedProxies []string `json:"trusted_proxies"`
+	// Headers adds standard hardening headers to HTML responses.
+	Headers SecurityHeaders `json:"headers"`
+	// CSP sends a Content-Security-Policy with HTML responses.
+	CSP CSP `json:"csp"`
+}
+
+// SecurityHeaders configures the hardening headers sent with every HTML
+// response. Empty values use the defaults; "off" drops a header.
+type SecurityHeaders struct {
+	Enabled bool `json:"enabled"`
+	// HSTSMaxAge is the Strict-Transport-Security max-age in seconds;
+	// defaults to one year, -1 disables HSTS. It is only sent when
+	// site.base_url is https.
+	HSTSMaxAge            int    `json:"hsts_max_age"`
+	HSTSIncludeSubdomains bool   `json:"hsts_include_subdomains"`
+	HSTSPreload           bool   `json:"hsts_preload"`
+	ReferrerPolicy        string `json:"referrer_policy"`
+	PermissionsPolicy     string `json:"permissions_policy"`
+	// FrameOptions is DENY (default) or SAMEORIGIN.
+	FrameOptions string `json:"frame_options"`
+}
+
+// CSP configures the Content-Security-Policy. Scripts carry a per-request
+// nonce, exposed to templates as {{.Nonce}}.
+type CSP struct {
+	Enabled bool `json:"enabled"`
+	// ReportOnly sends Content-Security-Policy-Report-Only so violations
+	// are reported without being blocked.
+	ReportOnly bool `json:"report_only"`
+	// Directives override DefaultCSPDirectives by name; an empty value
+	// removes the directive. 'nonce' expands to the request nonce.
+	Directives map[string]string `json:"directives"`
+	// ReportURI receives violation reports; defaults to /csp-report, which
+	// logs them. "off" disables reporting.
+	ReportURI string `json:"report_uri"`
+}
+
+// Directives returns the response headers for h. HSTS is included only
+// when https is set.
+func (h SecurityHeaders) Directives(https bool) map[string]string {
+	if !h.Enabled {
+		return nil
+	}
+
+	out := map[string]string{"X-Content-Type-Options": "nosniff"}
+	if https && h.HSTSMaxAge >= 0 {
+		hsts := "max-age=" + strconv.Itoa(h.HSTSMaxAge)
+		if h.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if h.HSTSPreload {
+			hsts += "; preload"
+		}
+		out["Strict-Transport-Security"] = hsts
+	}
+	for key, val := range map[string]string{
+		"Referrer-Policy":    h.ReferrerPolicy,
+		"Permissions-Policy": h.PermissionsPolicy,
+		"X-Frame-Options":    h.FrameOptions,
+	} {
+		if val != HeaderOff {
+			out[key] = val
+		}
+	}
+	return out
+}
+
+// Policy renders the policy with nonce substituted for 'nonce'. default-src
+// comes first, then the other directives in sorted order, then reporting.
+func (c CSP) Policy(nonce string) string {
+	merged := make(map[string]string, len(DefaultCSPDirectives)+len(c.Directives))
+	for name, val := range DefaultCSPDirectives {
+		merged[name] = val
+	}
+	for name, val := range c.Directives {
+		merged[name] = val
+	}
+
+	names := make([]string, 0, len(merged))
+	for name, val := range merged {
+		if val != "" && name != "default-src" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if merged["default-src"] != "" {
+		names = append([]string{"default-src"}, names...)
+	}
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + " " + strings.ReplaceAll(merged[name], CSPNonce, "'nonce-"+nonce+"'")
+	}
+	if c.Reporting() {
+		parts = append(parts, "report-uri "+c.ReportURI, "report-to "+CSPReportGroup)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Reporting reports whether violation reports are requested.
+func (c CSP) Reporting() bool {
+	return c.ReportURI != "" && c.ReportURI != HeaderOff
+}
+
+// HeaderName returns the CSP header to send.
+func (c CSP) HeaderName() string {
+	if c.ReportOnly {
+		return "Content-Security-Policy-Report-Only"
+	}
+	return "Content-Security-Policy"
+}
+
+// CSRF configures cross-site request forgery protection for form endpoints.
+// Origin checks always apply; signed tokens are opt-in.
+type CSRF struct {
+	// Tokens requires a signed token, rendered into pages with csrfField,
+	// in every same-site form submission.
+	Tokens bool `json:"tokens"`
+	// Secret keys the tokens (env: CSRF_SECRET).
+	Secret string `json:"secret"`
+	// MaxAgeMinutes bounds the token lifetime; defaults to 12 hours.
+	MaxAgeMinutes int `json:"max_age_minutes"`
+	// AllowedOrigins lists partner origins, such as
+	// "https://partner.example", that may post forms cross-site.
+	AllowedOrigins []string `json:"allowed_origins"`
+}
+
+func (c *Config) validateSecurity() error {
+	csrf := &c.Security.CSRF
+	csrf.Secret = strings.TrimSpace(csrf.Secret)
+
+	if csrf.MaxAgeMinutes < 0 {
+		return errors.New("security.csrf.max_age_minutes must not be negative")
+	}
+	if csrf.MaxAgeMinutes == 0 {
+		csrf.MaxAgeMinutes = DefaultCSRFMaxAge
+	}
+
+	for i, origin := range csrf.AllowedOrigins {
+		normalized, err := normalizeOrigin(origin)
+		if err != nil {
+			return fmt.Errorf("security.csrf.allowed_origins[%d]: %w", i, err)
+		}
+		csrf.AllowedOrigins[i] = normalized
+	}
+
+	if err := c.validateTrustedProxies(); err != nil {
+		return err
+	}
+	if err := c.Security.Headers.validate(); err != nil {
+		return err
+	}
+	return c.Security.CSP.validate()
+}
+
+func (h *SecurityHeaders) validate() error {
+	h.ReferrerPolicy = strings.TrimSpace(h.ReferrerPolicy)
+	h.PermissionsPolicy = strings.TrimSpace(h.PermissionsPolicy)
+	h.FrameOptions = strings.ToUpper(strings.TrimSpace(h.FrameOptions))
+
+	if h.HSTSMaxAge < -1 {
+		return errors.New("security.headers.hsts_max_age must be -1 (disabled) or more")
+	}
+	if h.HSTSMaxAge == 0 {
+		h.HSTSMaxAge = DefaultHSTSMaxAge
+	}
+	if h.ReferrerPolicy == "" {
+		h.ReferrerPolicy = DefaultReferrerPolicy
+	}
+	if h.PermissionsPolicy == "" {
+		h.PermissionsPolicy = DefaultPermissionsPolicy
+	}
+	switch h.FrameOptions {
+	case "":
+		h.FrameOptions = DefaultFrameOptions
+	case "DENY", "SAMEORIGIN":
+	case strings.ToUpper(HeaderOff):
+		h.FrameOptions = HeaderOff
+	default:
+		return fmt.Errorf("security.headers.frame_options must be DENY, SAMEORIGIN or off, got %q", h.FrameOptions)
+	}
+	return nil
+}
+
+var cspDirectivePattern = regexp.MustCompile(`^[a-z][a-z-]*$`)
+
+func (c *CSP) validate() error {
+	c.ReportURI = strings.TrimSpace(c.ReportURI)
+	if c.ReportURI == "" {
+		c.ReportURI = DefaultCSPReportURI
+	}
+	if c.ReportURI != HeaderOff && !isPathOrHTTPURL(c.ReportURI) {
+		return errors.New("security.csp.report_uri must be a path, an http(s) URL or off")
+	}
+
+	directives := make(map[string]string, len(c.Directives))
+	for name, val := range c.Directives {
+		name = strings.ToLower(strings.TrimSpace(name))
+		val = strings.Join(strings.Fields(val), " ")
+		if !cspDirectivePattern.MatchString(name) {
+			return fmt.Errorf("security.csp.directives: invalid directive name %q", name)
+		}
+		if name == "report-uri" || name == "report-to" {
+			return fmt.Errorf("security.csp.directives: set %s through security.csp.report_uri", name)
+		}
+		if strings.ContainsAny(val, ";,") {
+			return fmt.Errorf("security.csp.directives.%s must not contain ';' or ','", name)
+		}
+		directives[name] = val
+	}
+	c.Directives = directives
+	return nil
+}
+
+// normalizeOrigin lowercases a scheme://host[:port] origin and rejects
+// anything with a path, query or credentials.
+func normalizeOrigin(origin string) (string, error) {
+	u, err := url.Parse(strings.TrimRight(strings.TrimSpace(origin), "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%q must be an http(s) origin", origin)
+	}
+	if u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", fmt.Errorf("%q must not include a path, query or credentials", origin)
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host), nil
+}
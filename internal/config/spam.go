@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Spam configures the bot defenses of a form. Every check is off until it is
+// configured.
+type Spam struct {
+	// Honeypot names a hidden field that people leave empty.
+	Honeypot string `json:"honeypot"`
+	// MinSeconds rejects submissions sent sooner than this after the form
+	// token was issued. It requires the page to post a token from
+	// /form-token.
+	MinSeconds int `json:"min_seconds"`
+	// RateLimit bounds submissions per client IP.
+	RateLimit RateLimit `json:"rate_limit"`
+	// Blocklist lists words or link fragments, matched case-insensitively
+	// against every submitted value.
+	Blocklist []string `json:"blocklist"`
+	// MaxLinks caps the number of URLs in a submission when set.
+	MaxLinks *int `json:"max_links"`
+}
+
+// RateLimit is a token bucket: Burst submissions at once, refilled at
+// PerMinute.
+type RateLimit struct {
+	PerMinute float64 `json:"per_minute"`
+	Burst     int     `json:"burst"`
+}
+
+// Enabled reports whether rate limiting is configured.
+func (r RateLimit) Enabled() bool {
+	return r.PerMinute > 0
+}
+
+func (s Spam) isZero() bool {
+	return s.Honeypot == "" && s.MinSeconds == 0 && s.RateLimit == (RateLimit{}) &&
+		len(s.Blocklist) == 0 && s.MaxLinks == nil
+}
+
+func (s *Spam) validate(prefix string, fields []Field) error {
+	s.Honeypot = strings.TrimSpace(s.Honeypot)
+	if s.Honeypot != "" {
+		if !fieldNamePattern.MatchString(s.Honeypot) {
+			return fmt.Errorf("%s.spam.honeypot %q is not a valid field name", prefix, s.Honeypot)
+		}
+		for _, f := range fields {
+			if f.Name == s.Honeypot {
+				return fmt.Errorf("%s.spam.honeypot %q must not be a form field", prefix, s.Honeypot)
+			}
+		}
+	}
+
+	if s.MinSeconds < 0 {
+		return fmt.Errorf("%s.spam.min_seconds must not be negative", prefix)
+	}
+
+	if s.RateLimit.PerMinute < 0 || s.RateLimit.Burst < 0 {
+		return fmt.Errorf("%s.spam.rate_limit values must not be negative", prefix)
+	}
+	if s.RateLimit.Enabled() && s.RateLimit.Burst == 0 {
+		s.RateLimit.Burst = 1
+	}
+
+	blocklist := s.Blocklist[:0]
+	for _, term := range s.Blocklist {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term != "" {
+			blocklist = append(blocklist, term)
+		}
+	}
+	s.Blocklist = blocklist
+
+	if s.MaxLinks != nil && *s.MaxLinks < 0 {
+		return fmt.Errorf("%s.spam.max_links must not be negative", prefix)
+	}
+
+	return nil
+}
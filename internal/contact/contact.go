@@ -14,9 +14,32 @@ import (
 
 // Message represents a contact form submission.
 type Message struct {
-	Name  string
-	Email string
-	Body  string
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Body  string `json:"body"`
+	// Fields holds every submitted field when the message was built from a
+	// form schema by ParseForm.
+	Fields []Field `json:"fields,omitempty"`
+
+	// Form names the configured form the message was submitted through.
+	Form string `json:"form,omitempty"`
+	// Subject, To and CC override the backend configuration when set; the
+	// server fills them from the form's subject template and routing rules.
+	Subject string   `json:"subject,omitempty"`
+	To      string   `json:"to,omitempty"`
+	CC      []string `json:"cc,omitempty"`
+}
+
+// Values returns the submitted field values keyed by field name.
+func (m Message) Values() map[string]string {
+	values := make(map[string]string, len(m.Fields)+3)
+	values["name"] = m.Name
+	values["email"] = m.Email
+	values["message"] = m.Body
+	for _, f := range m.Fields {
+		values[f.Name] = f.Value
+	}
+	return values
 }
 
 // Sender defines behaviour required to deliver a contact message.
@@ -25,6 +48,18 @@ type Sender interface {
 	Send(ctx context.Context, msg Message) error
 }
 
+// New returns the Sender for the backend selected in cfg.
+func New(cfg config.Contact) Sender {
+	switch cfg.BackendName() {
+	case config.BackendSMTP:
+		return NewSMTP(cfg, nil)
+	case config.BackendWebhook:
+		return NewWebhook(cfg, nil)
+	default:
+		return NewService(cfg, nil)
+	}
+}
+
 // Service sends contact messages using Mailgun.
 type Service struct {
 	cfg config.Contact
@@ -57,46 +92,127 @@ func (s *Service) Send(ctx context.Context, msg Message) error {
 		return errors.New("contact service disabled")
 	}
 
+	msg, err := prepare(msg)
+	if err != nil {
+		return err
+	}
+
+	to, cc := addressees(s.cfg, msg)
+	message := mailgun.NewMessage(s.cfg.Mailgun.Domain, s.cfg.From, subjectFor(s.cfg, msg), buildPlainText(msg))
+	if err := message.AddRecipient(to); err != nil {
+		return fmt.Errorf("add recipient: %w", err)
+	}
+	for _, addr := range cc {
+		message.AddCC(addr)
+	}
+	message.SetReplyTo(msg.Email)
+	message.AddHeader("X-Originating-Email", msg.Email)
+
+	if _, err := s.mg.Send(ctx, message); err != nil {
+		return fmt.Errorf("mailgun send: %w", err)
+	}
+
+	return nil
+}
+
+// prepare trims msg and checks the fields every backend requires.
+func prepare(msg Message) (Message, error) {
 	msg.Name = strings.TrimSpace(msg.Name)
 	msg.Email = strings.TrimSpace(msg.Email)
 	msg.Body = strings.TrimSpace(msg.Body)
 
-	if msg.Name == "" || msg.Email == "" || msg.Body == "" {
-		return errors.New("name, email, and message are required")
+	if len(msg.Fields) == 0 && (msg.Name == "" || msg.Email == "" || msg.Body == "") {
+		return msg, errors.New("name, email, and message are required")
+	}
+	if msg.Email == "" {
+		return msg, errors.New("sender email is required")
 	}
 
 	if !strings.Contains(msg.Email, "@") {
-		return errors.New("sender email must contain '@'")
+		return msg, errors.New("sender email must contain '@'")
 	}
 
-	subject := s.cfg.Subject
-	if subject == "" {
-		subject = fmt.Sprintf("New contact from %s", msg.Name)
+	if strings.ContainsAny(msg.Name+msg.Email+msg.Subject+msg.To+strings.Join(msg.CC, ""), "\r\n") {
+		return msg, errors.New("name, email, subject and recipients must be a single line")
 	}
 
-	message := mailgun.NewMessage(s.cfg.Mailgun.Domain, s.cfg.From, subject, buildPlainText(msg))
-	if err := message.AddRecipient(s.cfg.Recipient); err != nil {
-		return fmt.Errorf("add recipient: %w", err)
+	return msg, nil
+}
+
+func subjectFor(cfg config.Contact, msg Message) string {
+	if msg.Subject != "" {
+		return msg.Subject
 	}
-	message.SetReplyTo(msg.Email)
-	message.AddHeader("X-Originating-Email", msg.Email)
+	if cfg.Subject != "" && !strings.Contains(cfg.Subject, "{{") {
+		return cfg.Subject
+	}
+	if msg.Name == "" {
+		return fmt.Sprintf("New contact from %s", msg.Email)
+	}
+	return fmt.Sprintf("New contact from %s", msg.Name)
+}
 
-	if _, err := s.mg.Send(ctx, message); err != nil {
-		return fmt.Errorf("mailgun send: %w", err)
+// addressees returns the primary recipient and the de-duplicated copy list:
+// the message overrides first, then the configured defaults.
+func addressees(cfg config.Contact, msg Message) (string, []string) {
+	to := msg.To
+	if to == "" {
+		to = cfg.Recipient
 	}
 
-	return nil
+	seen := map[string]struct{}{strings.ToLower(to): {}}
+	var cc []string
+	for _, addr := range append(append([]string(nil), cfg.CC...), msg.CC...) {
+		key := strings.ToLower(strings.TrimSpace(addr))
+		if key == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		cc = append(cc, strings.TrimSpace(addr))
+	}
+
+	return to, cc
 }
 
 func buildPlainText(msg Message) string {
 	var b strings.Builder
-	b.WriteString("Name: ")
-	b.WriteString(msg.Name)
-	b.WriteString("\nEmail: ")
-	b.WriteString(msg.Email)
-	b.WriteString("\nSubmitted: ")
+	if len(msg.Fields) == 0 {
+		b.WriteString("Name: ")
+		b.WriteString(msg.Name)
+		b.WriteString("\nEmail: ")
+		b.WriteString(msg.Email)
+		b.WriteString("\nSubmitted: ")
+		b.WriteString(time.Now().UTC().Format(time.RFC3339))
+		b.WriteString("\n\n")
+		b.WriteString(msg.Body)
+		return b.String()
+	}
+
+	// Multi-line values (textareas) are written after the single-line ones
+	// so the summary stays readable.
+	var long []Field
+	for _, f := range msg.Fields {
+		if strings.Contains(f.Value, "\n") {
+			long = append(long, f)
+			continue
+		}
+		b.WriteString(f.Label)
+		b.WriteString(": ")
+		b.WriteString(f.Value)
+		b.WriteString("\n")
+	}
+	b.WriteString("Submitted: ")
 	b.WriteString(time.Now().UTC().Format(time.RFC3339))
-	b.WriteString("\n\n")
-	b.WriteString(msg.Body)
-	return b.String()
+	b.WriteString("\n")
+	for _, f := range long {
+		b.WriteString("\n")
+		b.WriteString(f.Label)
+		b.WriteString(":\n")
+		b.WriteString(f.Value)
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
 }
@@ -0,0 +1,127 @@
+package contact
+
+import (
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+// Field is one submitted form value as delivered in a Message.
+type Field struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// FieldErrors maps field names to a human-readable problem.
+type FieldErrors map[string]string
+
+var telPattern = regexp.MustCompile(`^\+?[0-9 ().\-/]{5,32}$`)
+
+// ParseForm validates values against the form schema and builds the message
+// to deliver. Name, Email and Body are filled from the fields called "name",
+// the first email field and "message"; every field is listed in Fields in
+// schema order. The returned errors are nil when the submission is valid.
+func ParseForm(fields []config.Field, values url.Values) (Message, FieldErrors) {
+	var (
+		msg  Message
+		errs = FieldErrors{}
+	)
+
+	for _, f := range fields {
+		value, problem := checkField(f, values)
+		if problem != "" {
+			errs[f.Name] = problem
+			continue
+		}
+
+		msg.Fields = append(msg.Fields, Field{Name: f.Name, Label: f.DisplayLabel(), Value: value})
+
+		switch {
+		case f.Type == config.FieldEmail && msg.Email == "":
+			msg.Email = value
+		case f.Name == "name":
+			msg.Name = value
+		case f.Name == "message":
+			msg.Body = value
+		}
+	}
+
+	if len(errs) > 0 {
+		return Message{}, errs
+	}
+	return msg, nil
+}
+
+// checkField returns the normalised value of f or a description of why it is
+// invalid.
+func checkField(f config.Field, values url.Values) (string, string) {
+	raw := strings.TrimSpace(values.Get(f.Name))
+
+	if f.Type == config.FieldCheckbox {
+		checked := raw != "" && raw != "0" && !strings.EqualFold(raw, "false") && !strings.EqualFold(raw, "off")
+		if f.Required && !checked {
+			return "", "must be accepted"
+		}
+		if checked {
+			return "yes", ""
+		}
+		return "no", ""
+	}
+
+	if raw == "" {
+		if f.Required {
+			return "", "is required"
+		}
+		return "", ""
+	}
+
+	if strings.ContainsAny(raw, "\r\n") && f.Type != config.FieldTextarea {
+		return "", "must be a single line"
+	}
+
+	length := utf8.RuneCountInString(raw)
+	if f.MinLength > 0 && length < f.MinLength {
+		return "", "must be at least " + strconv.Itoa(f.MinLength) + " characters"
+	}
+	if f.MaxLength > 0 && length > f.MaxLength {
+		return "", "must be at most " + strconv.Itoa(f.MaxLength) + " characters"
+	}
+
+	switch f.Type {
+	case config.FieldEmail:
+		addr, err := mail.ParseAddress(raw)
+		if err != nil || addr.Name != "" || addr.Address != raw {
+			return "", "must be a valid email address"
+		}
+	case config.FieldTel:
+		if !telPattern.MatchString(raw) {
+			return "", "must be a valid phone number"
+		}
+	case config.FieldURL:
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", "must be an http(s) URL"
+		}
+	case config.FieldNumber:
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return "", "must be a number"
+		}
+	}
+
+	if len(f.Options) > 0 && !slices.Contains(f.Options, raw) {
+		return "", "must be one of the listed options"
+	}
+
+	if re := f.Regexp(); re != nil && !re.MatchString(raw) {
+		return "", "has an invalid format"
+	}
+
+	return raw, ""
+}
@@ -0,0 +1,83 @@
+package contact
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+func TestParseForm(t *testing.T) {
+	fields := []config.Field{
+		{Name: "name", Label: "Name", Required: true, MaxLength: 20},
+		{Name: "email", Label: "Email", Type: config.FieldEmail, Required: true},
+		{Name: "phone", Label: "Phone", Type: config.FieldTel},
+		{Name: "budget", Label: "Budget", Type: config.FieldSelect, Options: []string{"<10k", "10-50k"}},
+		{Name: "code", Label: "Code", Pattern: `[A-Z]{3}`},
+		{Name: "consent", Label: "Consent", Type: config.FieldCheckbox, Required: true},
+		{Name: "message", Label: "Message", Type: config.FieldTextarea, MinLength: 5},
+	}
+
+	msg, errs := ParseForm(fields, url.Values{
+		"name":    {" Jane "},
+		"email":   {"jane@example.com"},
+		"phone":   {"+39 02 1234 5678"},
+		"budget":  {"10-50k"},
+		"consent": {"on"},
+		"message": {"Hello there\nSecond line"},
+		"extra":   {"ignored"},
+	})
+	if errs != nil {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if msg.Name != "Jane" || msg.Email != "jane@example.com" || msg.Body != "Hello there\nSecond line" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	if len(msg.Fields) != len(fields) {
+		t.Fatalf("expected every field in the message, got %+v", msg.Fields)
+	}
+	if msg.Fields[5] != (Field{Name: "consent", Label: "Consent", Value: "yes"}) || msg.Fields[4].Value != "" {
+		t.Fatalf("unexpected field values: %+v", msg.Fields)
+	}
+
+	text := buildPlainText(msg)
+	for _, want := range []string{"Name: Jane\n", "Phone: +39 02 1234 5678\n", "Budget: 10-50k\n", "Message:\nHello there\nSecond line"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("plain text missing %q:\n%s", want, text)
+		}
+	}
+
+	_, errs = ParseForm(fields, url.Values{
+		"name":    {strings.Repeat("x", 21)},
+		"email":   {"Jane <jane@example.com>"},
+		"phone":   {"call me"},
+		"budget":  {"1M"},
+		"code":    {"abc"},
+		"message": {"Hi"},
+	})
+	want := map[string]string{
+		"name":    "must be at most 20 characters",
+		"email":   "must be a valid email address",
+		"phone":   "must be a valid phone number",
+		"budget":  "must be one of the listed options",
+		"code":    "has an invalid format",
+		"consent": "must be accepted",
+		"message": "must be at least 5 characters",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	for name, problem := range want {
+		if errs[name] != problem {
+			t.Fatalf("field %s: got %q, want %q", name, errs[name], problem)
+		}
+	}
+}
+
+func TestParseFormDefaultFields(t *testing.T) {
+	_, errs := ParseForm(config.DefaultFields, url.Values{"email": {"not-an-email"}})
+	if errs["name"] != "is required" || errs["message"] != "is required" || errs["email"] == "" {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
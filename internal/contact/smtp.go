@@ -0,0 +1,286 @@
+package contact
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+// SMTPSender sends contact messages through an SMTP relay.
+type SMTPSender struct {
+	cfg       config.Contact
+	tlsConfig *tls.Config
+}
+
+// NewSMTP constructs an SMTPSender. tlsConfig may be nil, in which case the
+// system roots are used and the server name is taken from cfg.SMTP.Host.
+func NewSMTP(cfg config.Contact, tlsConfig *tls.Config) *SMTPSender {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = cfg.SMTP.Host
+	}
+	return &SMTPSender{cfg: cfg, tlsConfig: tlsConfig}
+}
+
+// Enabled reports whether the sender has sufficient configuration to send messages.
+func (s *SMTPSender) Enabled() bool {
+	return s != nil && s.cfg.BackendName() == config.BackendSMTP && s.cfg.Enabled()
+}
+
+// Send delivers a contact message over SMTP.
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if s == nil {
+		return errors.New("contact service is nil")
+	}
+	if !s.Enabled() {
+		return errors.New("contact service disabled")
+	}
+
+	msg, err := prepare(msg)
+	if err != nil {
+		return err
+	}
+
+	from, err := mail.ParseAddress(s.cfg.From)
+	if err != nil {
+		return fmt.Errorf("parse from address: %w", err)
+	}
+	recipient, copies := addressees(s.cfg, msg)
+	to, err := mail.ParseAddress(recipient)
+	if err != nil {
+		return fmt.Errorf("parse recipient address: %w", err)
+	}
+	cc := make([]*mail.Address, 0, len(copies))
+	for _, addr := range copies {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return fmt.Errorf("parse cc address: %w", err)
+		}
+		cc = append(cc, parsed)
+	}
+	replyTo, err := mail.ParseAddress(msg.Email)
+	if err != nil {
+		return fmt.Errorf("parse sender email: %w", err)
+	}
+	replyTo.Name = msg.Name
+
+	data, err := s.buildMessage(from, to, cc, replyTo, subjectFor(s.cfg, msg), buildPlainText(msg))
+	if err != nil {
+		return err
+	}
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	rcpts := []string{to.Address}
+	for _, addr := range cc {
+		rcpts = append(rcpts, addr.Address)
+	}
+
+	if err := s.deliver(client, from.Address, rcpts, data); err != nil {
+		return fmt.Errorf("smtp send: %w", err)
+	}
+
+	return nil
+}
+
+// dial connects to the relay and, for starttls, upgrades the connection.
+func (s *SMTPSender) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.cfg.SMTP.Host, strconv.Itoa(s.port()))
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if s.cfg.SMTP.TLS == config.SMTPImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: s.tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("smtp dial %s: %w", addr, err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.SMTP.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp handshake: %w", err)
+	}
+
+	if s.cfg.SMTP.TLS == config.SMTPStartTLS || s.cfg.SMTP.TLS == "" {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, errors.New("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(s.tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+func (s *SMTPSender) deliver(client *smtp.Client, from string, to []string, data []byte) error {
+	if s.cfg.SMTP.Username != "" {
+		var auth smtp.Auth
+		if s.cfg.SMTP.Auth == "login" {
+			auth = &loginAuth{username: s.cfg.SMTP.Username, password: s.cfg.SMTP.Password, host: s.cfg.SMTP.Host}
+		} else {
+			auth = smtp.PlainAuth("", s.cfg.SMTP.Username, s.cfg.SMTP.Password, s.cfg.SMTP.Host)
+		}
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (s *SMTPSender) port() int {
+	if s.cfg.SMTP.Port != 0 {
+		return s.cfg.SMTP.Port
+	}
+	switch s.cfg.SMTP.TLS {
+	case config.SMTPImplicit:
+		return 465
+	case config.SMTPNoTLS:
+		return 25
+	default:
+		return 587
+	}
+}
+
+func (s *SMTPSender) buildMessage(from, to *mail.Address, cc []*mail.Address, replyTo *mail.Address, subject, body string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	header := func(key, value string) {
+		buf.WriteString(key)
+		buf.WriteString(": ")
+		buf.WriteString(value)
+		buf.WriteString("\r\n")
+	}
+
+	header("From", from.String())
+	header("To", to.String())
+	if len(cc) > 0 {
+		list := make([]string, len(cc))
+		for i, addr := range cc {
+			list[i] = addr.String()
+		}
+		header("Cc", strings.Join(list, ", "))
+	}
+	header("Reply-To", replyTo.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", time.Now().UTC().Format(time.RFC1123Z))
+	header("Message-ID", messageID(from.Address))
+	header("X-Originating-Email", replyTo.Address)
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return nil, fmt.Errorf("encode body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return nil, fmt.Errorf("encode body: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndexByte(from, '@'); at >= 0 {
+		domain = from[at+1:]
+	}
+
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	return "<" + hex.EncodeToString(b[:]) + "@" + domain + ">"
+}
+
+// loginAuth implements the LOGIN mechanism, which net/smtp does not provide.
+// Like smtp.PlainAuth it refuses to send credentials over an unencrypted
+// connection to anything other than localhost.
+type loginAuth struct {
+	username, password, host string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch string(bytes.ToLower(bytes.TrimSpace(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
@@ -0,0 +1,253 @@
+package contact
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+func TestSMTPSenderStartTLSPlain(t *testing.T) {
+	cert, roots := testCertificate(t)
+	srv := startFakeSMTP(t, cert, false)
+
+	sender := NewSMTP(smtpConfig(srv.port, config.SMTPStartTLS, "plain"), &tls.Config{RootCAs: roots})
+	if !sender.Enabled() {
+		t.Fatal("expected SMTP sender to be enabled")
+	}
+
+	if err := sender.Send(context.Background(), Message{Name: "Jane", Email: "jane@example.com", Body: "Hi there"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	got := <-srv.sessions
+	if !got.tls || !got.startTLS {
+		t.Fatalf("expected STARTTLS upgrade, got %+v", got)
+	}
+	if got.auth != "PLAIN user secret" {
+		t.Fatalf("unexpected auth: %q", got.auth)
+	}
+	if got.from != "no-reply@example.com" || got.to != "owners@example.com" {
+		t.Fatalf("unexpected envelope: from=%q to=%q", got.from, got.to)
+	}
+	for _, want := range []string{
+		"Subject: Website enquiry",
+		`Reply-To: "Jane" <jane@example.com>`,
+		"Content-Type: text/plain; charset=utf-8",
+		"Hi there",
+	} {
+		if !strings.Contains(got.data, want) {
+			t.Fatalf("message missing %q:\n%s", want, got.data)
+		}
+	}
+}
+
+func TestSMTPSenderRoutedRecipients(t *testing.T) {
+	cert, roots := testCertificate(t)
+	srv := startFakeSMTP(t, cert, false)
+
+	cfg := smtpConfig(srv.port, config.SMTPStartTLS, "plain")
+	cfg.CC = []string{"owner@example.com", "sales@example.com"}
+	sender := NewSMTP(cfg, &tls.Config{RootCAs: roots})
+
+	msg := Message{Name: "Jane", Email: "jane@example.com", Body: "Hi", Subject: "Quote: sales", To: "sales@example.com", CC: []string{"Owner@example.com", "boss@example.com"}}
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	got := <-srv.sessions
+	if got.to != "sales@example.com,owner@example.com,boss@example.com" {
+		t.Fatalf("unexpected recipients: %q", got.to)
+	}
+	for _, want := range []string{"To: <sales@example.com>", "Cc: <owner@example.com>, <boss@example.com>", "Subject: Quote: sales"} {
+		if !strings.Contains(got.data, want) {
+			t.Fatalf("message missing %q:\n%s", want, got.data)
+		}
+	}
+}
+
+func TestSMTPSenderImplicitTLSLogin(t *testing.T) {
+	cert, roots := testCertificate(t)
+	srv := startFakeSMTP(t, cert, true)
+
+	sender := NewSMTP(smtpConfig(srv.port, config.SMTPImplicit, "login"), &tls.Config{RootCAs: roots})
+	if err := sender.Send(context.Background(), Message{Name: "Jane", Email: "jane@example.com", Body: "Hi"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	got := <-srv.sessions
+	if !got.tls || got.startTLS {
+		t.Fatalf("expected implicit TLS, got %+v", got)
+	}
+	if got.auth != "LOGIN user secret" {
+		t.Fatalf("unexpected auth: %q", got.auth)
+	}
+}
+
+func TestSMTPSenderRejectsUntrustedCertificate(t *testing.T) {
+	cert, _ := testCertificate(t)
+	srv := startFakeSMTP(t, cert, false)
+
+	sender := NewSMTP(smtpConfig(srv.port, config.SMTPStartTLS, "plain"), &tls.Config{RootCAs: x509.NewCertPool()})
+	err := sender.Send(context.Background(), Message{Name: "Jane", Email: "jane@example.com", Body: "Hi"})
+	if err == nil || !strings.Contains(err.Error(), "starttls") {
+		t.Fatalf("expected STARTTLS verification failure, got %v", err)
+	}
+}
+
+func smtpConfig(port int, mode, auth string) config.Contact {
+	return config.Contact{
+		Backend:   config.BackendSMTP,
+		Recipient: "owners@example.com",
+		From:      "Landing Page <no-reply@example.com>",
+		Subject:   "Website enquiry",
+		SMTP: config.SMTP{
+			Host:     "127.0.0.1",
+			Port:     port,
+			TLS:      mode,
+			Username: "user",
+			Password: "secret",
+			Auth:     auth,
+		},
+	}
+}
+
+// testCertificate borrows the httptest certificate, which is valid for
+// 127.0.0.1, along with a pool that trusts it.
+func testCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	defer ts.Close()
+	return ts.TLS.Certificates[0], ts.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+}
+
+type smtpSession struct {
+	tls, startTLS bool
+	auth          string
+	from, to      string
+	data          string
+}
+
+type fakeSMTP struct {
+	port     int
+	sessions chan smtpSession
+}
+
+// startFakeSMTP runs a single-connection SMTP server that records what the
+// client sent. With implicit set the listener speaks TLS from the start;
+// otherwise it offers STARTTLS.
+func startFakeSMTP(t *testing.T, cert tls.Certificate, implicit bool) *fakeSMTP {
+	t.Helper()
+
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	if implicit {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	srv := &fakeSMTP{port: ln.Addr().(*net.TCPAddr).Port, sessions: make(chan smtpSession, 1)}
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		session := smtpSession{tls: implicit}
+		tp := textproto.NewConn(conn)
+		reply := func(code int, lines ...string) {
+			for i, line := range lines {
+				sep := "-"
+				if i == len(lines)-1 {
+					sep = " "
+				}
+				_ = tp.PrintfLine("%d%s%s", code, sep, line)
+			}
+		}
+		decode := func(s string) string {
+			b, _ := base64.StdEncoding.DecodeString(s)
+			return string(b)
+		}
+
+		reply(220, "localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(line, " ")
+
+			switch strings.ToUpper(verb) {
+			case "EHLO":
+				ext := []string{"localhost", "AUTH PLAIN LOGIN"}
+				if !session.tls {
+					ext = append(ext, "STARTTLS")
+				}
+				reply(250, ext...)
+			case "STARTTLS":
+				reply(220, "ready")
+				tlsConn := tls.Server(conn, tlsConfig)
+				if err := tlsConn.Handshake(); err != nil {
+					return
+				}
+				conn = tlsConn
+				tp = textproto.NewConn(conn)
+				session.tls, session.startTLS = true, true
+			case "AUTH":
+				mech, initial, _ := strings.Cut(arg, " ")
+				if mech == "PLAIN" {
+					parts := strings.Split(decode(initial), "\x00")
+					session.auth = "PLAIN " + strings.Join(parts[1:], " ")
+				} else {
+					reply(334, base64.StdEncoding.EncodeToString([]byte("Username:")))
+					user, _ := tp.ReadLine()
+					reply(334, base64.StdEncoding.EncodeToString([]byte("Password:")))
+					pass, _ := tp.ReadLine()
+					session.auth = "LOGIN " + decode(user) + " " + decode(pass)
+				}
+				reply(235, "authenticated")
+			case "MAIL":
+				session.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+				if idx := strings.Index(session.from, ">"); idx >= 0 {
+					session.from = session.from[:idx]
+				}
+				reply(250, "ok")
+			case "RCPT":
+				if session.to != "" {
+					session.to += ","
+				}
+				session.to += strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+				reply(250, "ok")
+			case "DATA":
+				reply(354, "go ahead")
+				data, _ := io.ReadAll(bufio.NewReader(tp.DotReader()))
+				session.data = string(data)
+				reply(250, "queued as "+strconv.Itoa(len(data)))
+			case "QUIT":
+				reply(221, "bye")
+				srv.sessions <- session
+				return
+			default:
+				reply(502, "unsupported")
+			}
+		}
+	}()
+
+	return srv
+}
@@ -0,0 +1,121 @@
+package contact
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+// DefaultSignatureHeader carries the webhook payload signature when the
+// configuration does not name another header.
+const DefaultSignatureHeader = "X-Signature-256"
+
+// WebhookSender posts contact messages as JSON to an HTTP endpoint.
+type WebhookSender struct {
+	cfg    config.Contact
+	client *http.Client
+}
+
+// WebhookPayload is the JSON document posted by WebhookSender.
+type WebhookPayload struct {
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	Message     string    `json:"message"`
+	Subject     string    `json:"subject"`
+	Recipient   string    `json:"recipient,omitempty"`
+	CC          []string  `json:"cc,omitempty"`
+	Form        string    `json:"form,omitempty"`
+	Fields      []Field   `json:"fields,omitempty"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// NewWebhook constructs a WebhookSender. When client is nil a client with a
+// ten second timeout is used.
+func NewWebhook(cfg config.Contact, client *http.Client) *WebhookSender {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookSender{cfg: cfg, client: client}
+}
+
+// Enabled reports whether the sender has sufficient configuration to send messages.
+func (s *WebhookSender) Enabled() bool {
+	return s != nil && s.cfg.BackendName() == config.BackendWebhook && s.cfg.Enabled()
+}
+
+// Send posts the message to the webhook. The body is signed with
+// HMAC-SHA256 using the configured secret; any non-2xx response is an error.
+func (s *WebhookSender) Send(ctx context.Context, msg Message) error {
+	if s == nil {
+		return errors.New("contact service is nil")
+	}
+	if !s.Enabled() {
+		return errors.New("contact service disabled")
+	}
+
+	msg, err := prepare(msg)
+	if err != nil {
+		return err
+	}
+
+	to, cc := addressees(s.cfg, msg)
+	body, err := json.Marshal(WebhookPayload{
+		Name:        msg.Name,
+		Email:       msg.Email,
+		Message:     msg.Body,
+		Subject:     subjectFor(s.cfg, msg),
+		Recipient:   to,
+		CC:          cc,
+		Form:        msg.Form,
+		Fields:      msg.Fields,
+		SubmittedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+
+	header := s.cfg.Webhook.Header
+	if header == "" {
+		header = DefaultSignatureHeader
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LandingGo-Webhook")
+	req.Header.Set(header, Sign(s.cfg.Webhook.Secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook send: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook send: unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by
+// the hex-encoded HMAC-SHA256 of body keyed with secret. Receivers should
+// recompute it over the raw request body and compare with hmac.Equal.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
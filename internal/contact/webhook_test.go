@@ -0,0 +1,93 @@
+package contact
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+func TestWebhookSenderSignsPayload(t *testing.T) {
+	type request struct {
+		signature string
+		body      []byte
+	}
+	received := make(chan request, 1)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		received <- request{signature: r.Header.Get("X-Landing-Signature"), body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(ts.Close)
+
+	cfg := config.Contact{
+		Backend:   config.BackendWebhook,
+		Recipient: "owners@example.com",
+		Webhook:   config.Webhook{URL: ts.URL + "/hook", Secret: "shh", Header: "X-Landing-Signature"},
+	}
+
+	sender := NewWebhook(cfg, ts.Client())
+	if err := sender.Send(context.Background(), Message{Name: " Jane ", Email: "jane@example.com", Body: "Hi"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	got := <-received
+	if !hmac.Equal([]byte(got.signature), []byte(Sign("shh", got.body))) {
+		t.Fatalf("signature %q does not match body", got.signature)
+	}
+	if !strings.HasPrefix(got.signature, "sha256=") {
+		t.Fatalf("unexpected signature format: %q", got.signature)
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(got.body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Name != "Jane" || payload.Email != "jane@example.com" || payload.Message != "Hi" ||
+		payload.Subject != "New contact from Jane" || payload.Recipient != "owners@example.com" || payload.SubmittedAt.IsZero() {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestWebhookSenderErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	t.Cleanup(ts.Close)
+
+	cfg := config.Contact{Backend: config.BackendWebhook, Webhook: config.Webhook{URL: ts.URL, Secret: "shh"}}
+	err := NewWebhook(cfg, ts.Client()).Send(context.Background(), Message{Name: "Jane", Email: "jane@example.com", Body: "Hi"})
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+
+	cfg.Webhook.Secret = ""
+	if NewWebhook(cfg, nil).Enabled() {
+		t.Fatal("webhook without a secret should be disabled")
+	}
+}
+
+func TestNewSelectsBackend(t *testing.T) {
+	cases := map[string]string{
+		"":                    "*contact.Service",
+		config.BackendMailgun: "*contact.Service",
+		config.BackendSMTP:    "*contact.SMTPSender",
+		config.BackendWebhook: "*contact.WebhookSender",
+	}
+	for backend, want := range cases {
+		if got := fmt.Sprintf("%T", New(config.Contact{Backend: backend})); got != want {
+			t.Fatalf("backend %q: got %s, want %s", backend, got, want)
+		}
+	}
+}
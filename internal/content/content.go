@@ -0,0 +1,142 @@
+package content
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/elchemista/LandingGo/internal/markdown"
+)
+
+// Dir is the directory, relative to the asset root, holding collections.
+// Each collection is a subdirectory of entries.
+const Dir = "content"
+
+// Entry is one item of a collection: a JSON object or a Markdown file with
+// front matter.
+type Entry struct {
+	// Slug identifies the entry in its route. It is the file name without
+	// extension unless a "slug" field overrides it.
+	Slug string
+	// Fields are the JSON object or front matter fields.
+	Fields Fields
+	// HTML is the rendered Markdown body, or the "body" field of a JSON
+	// entry.
+	HTML []byte
+	// File is the entry's path within the asset root, such as
+	// content/work/acme.md.
+	File string
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Load reads every .json and .md entry directly inside the named collection
+// directory of fsys, sorted by slug.
+func Load(fsys fs.FS, collection string) ([]Entry, error) {
+	dir := path.Join(Dir, collection)
+	files, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("collection %s: directory %s not found", collection, dir)
+		}
+		return nil, fmt.Errorf("collection %s: %w", collection, err)
+	}
+
+	seen := make(map[string]string, len(files))
+	entries := make([]Entry, 0, len(files))
+
+	for _, f := range files {
+		ext := path.Ext(f.Name())
+		if f.IsDir() || (ext != ".json" && ext != ".md") {
+			continue
+		}
+
+		name := path.Join(dir, f.Name())
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+
+		entry, err := Parse(name, data)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[entry.Slug]; ok {
+			return nil, fmt.Errorf("%s: slug %q is already used by %s", name, entry.Slug, other)
+		}
+		seen[entry.Slug] = name
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Slug < entries[j].Slug })
+	return entries, nil
+}
+
+// Parse decodes a single entry. name is its path within the asset root and
+// selects the format by extension.
+func Parse(name string, data []byte) (Entry, error) {
+	entry := Entry{File: name}
+
+	switch path.Ext(name) {
+	case ".json":
+		if err := json.Unmarshal(data, &entry.Fields); err != nil || entry.Fields == nil {
+			return Entry{}, fmt.Errorf("%s: entry must be a JSON object", name)
+		}
+		if body, ok := entry.Fields["body"].(string); ok {
+			entry.HTML = markdown.Render([]byte(body))
+		}
+	case ".md":
+		fields, body, err := ParseFrontMatter(data)
+		if err != nil {
+			return Entry{}, fmt.Errorf("%s: %w", name, err)
+		}
+		entry.Fields = fields
+		entry.HTML = markdown.Render(body)
+	default:
+		return Entry{}, fmt.Errorf("%s: unsupported entry type", name)
+	}
+
+	entry.Slug = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	if slug, ok := entry.Fields["slug"]; ok {
+		s, isString := slug.(string)
+		if !isString {
+			return Entry{}, fmt.Errorf("%s: slug must be a string", name)
+		}
+		entry.Slug = s
+	}
+	if !slugPattern.MatchString(entry.Slug) {
+		return Entry{}, fmt.Errorf("%s: slug %q must contain only lowercase letters, digits, '-' or '_'", name, entry.Slug)
+	}
+
+	return entry, nil
+}
+
+// String returns the named field when it is a string.
+func (e Entry) String(field string) string {
+	return e.Fields.String(field)
+}
+
+// Bool returns the named field when it is a boolean.
+func (e Entry) Bool(field string) bool {
+	return e.Fields.Bool(field)
+}
+
+// Fields are the decoded fields of an entry or a page's front matter.
+type Fields map[string]any
+
+// String returns the named field when it is a string.
+func (f Fields) String(field string) string {
+	s, _ := f[field].(string)
+	return strings.TrimSpace(s)
+}
+
+// Bool returns the named field when it is a boolean.
+func (f Fields) Bool(field string) bool {
+	b, _ := f[field].(bool)
+	return b
+}
@@ -0,0 +1,120 @@
+package content
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	src := []byte(`---
+title: "Acme: rebrand"
+year: 2024
+featured: true
+tags: [branding, "web, mobile"]
+# comments are skipped
+summary: plain text
+---
+Body text.
+`)
+
+	fields, body, err := ParseFrontMatter(src)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	want := Fields{
+		"title":    "Acme: rebrand",
+		"year":     float64(2024),
+		"featured": true,
+		"tags":     []any{"branding", "web, mobile"},
+		"summary":  "plain text",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Fatalf("fields = %#v, want %#v", fields, want)
+	}
+	if string(body) != "Body text.\n" {
+		t.Fatalf("body = %q", body)
+	}
+
+	fields, body, err = ParseFrontMatter([]byte("No front matter.\n"))
+	if err != nil || len(fields) != 0 || string(body) != "No front matter.\n" {
+		t.Fatalf("document without front matter: %v, %v, %q", err, fields, body)
+	}
+
+	fields, body, err = ParseFrontMatter([]byte("{\n  \"title\": \"Pricing\",\n  \"draft\": true,\n  \"meta\": {\"og\": \"x\"}\n}\n# Plans\n"))
+	if err != nil {
+		t.Fatalf("parse JSON front matter: %v", err)
+	}
+	if fields.String("title") != "Pricing" || !fields.Bool("draft") || fields["meta"] == nil || string(body) != "# Plans\n" {
+		t.Fatalf("unexpected JSON front matter: %v, %q", fields, body)
+	}
+
+	for _, bad := range []string{
+		"---\ntitle: Acme\n",
+		"---\njust text\n---\n",
+		"---\ntags: [a, b\n---\n",
+		"---\nmeta:\n  nested: true\n---\n",
+		"{\"title\": \"Acme\"\n",
+		"{\"title\": \"Acme\"} trailing\n",
+	} {
+		if _, _, err := ParseFrontMatter([]byte(bad)); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/work/acme.md":       {Data: []byte("---\ntitle: Acme\n---\n**Rebrand** for Acme.\n")},
+		"content/work/globex.json":   {Data: []byte(`{"title": "Globex", "slug": "globex-corp", "body": "A *new* site."}`)},
+		"content/work/notes.txt":     {Data: []byte("ignored")},
+		"content/work/drafts/old.md": {Data: []byte("ignored")},
+	}
+
+	entries, err := Load(fsys, "work")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	acme, globex := entries[0], entries[1]
+	if acme.Slug != "acme" || acme.String("title") != "Acme" || acme.File != "content/work/acme.md" {
+		t.Fatalf("unexpected markdown entry: %+v", acme)
+	}
+	if !strings.Contains(string(acme.HTML), "<strong>Rebrand</strong>") {
+		t.Fatalf("markdown body not rendered: %s", acme.HTML)
+	}
+	if globex.Slug != "globex-corp" || !strings.Contains(string(globex.HTML), "<em>new</em>") {
+		t.Fatalf("unexpected JSON entry: %+v", globex)
+	}
+
+	if _, err := Load(fsys, "missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected missing collection error, got %v", err)
+	}
+
+	fsys["content/work/acme-copy.json"] = &fstest.MapFile{Data: []byte(`{"slug": "acme"}`)}
+	if _, err := Load(fsys, "work"); err == nil || !strings.Contains(err.Error(), "already used") {
+		t.Fatalf("expected duplicate slug error, got %v", err)
+	}
+}
+
+func TestParseRejectsInvalidEntries(t *testing.T) {
+	cases := map[string]string{
+		"content/work/list.json":  `["not", "an", "object"]`,
+		"content/work/slug.json":  `{"slug": 3}`,
+		"content/work/Upper.md":   "Body",
+		"content/work/spaces.md":  "---\nslug: has spaces\n---\n",
+		"content/work/broken.md":  "---\ntitle: Acme\n",
+		"content/work/broken.txt": "Body",
+	}
+
+	for name, data := range cases {
+		if _, err := Parse(name, []byte(data)); err == nil || !strings.Contains(err.Error(), name) {
+			t.Fatalf("Parse(%s): expected error naming the file, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package content
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// frontMatterDelim opens and closes the front matter block.
+const frontMatterDelim = "---"
+
+// ParseFrontMatter splits a Markdown document into its front matter fields
+// and body. The front matter is an optional block at the top of the file,
+// either a JSON object or a block between two "---" lines holding
+// "key: value" pairs. Values in the latter may be quoted or bare strings,
+// numbers, true or false, or inline lists such as "[branding, web]"; nested
+// maps are not supported.
+func ParseFrontMatter(src []byte) (Fields, []byte, error) {
+	src = bytes.TrimPrefix(src, []byte("\ufeff"))
+	fields := make(Fields)
+
+	first, rest, _ := bytes.Cut(src, []byte("\n"))
+	if line := strings.TrimSpace(string(first)); line == "{" || strings.HasPrefix(line, `{"`) {
+		return parseJSONFrontMatter(src)
+	}
+	if strings.TrimSpace(string(first)) != frontMatterDelim {
+		return fields, src, nil
+	}
+
+	for n := 2; ; n++ {
+		if len(rest) == 0 {
+			return nil, nil, fmt.Errorf("front matter: missing closing %q", frontMatterDelim)
+		}
+
+		var line []byte
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+		text := strings.TrimRight(string(line), " \t\r")
+
+		if strings.TrimSpace(text) == frontMatterDelim {
+			return fields, rest, nil
+		}
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(strings.TrimSpace(text), "#") {
+			continue
+		}
+		if text[0] == ' ' || text[0] == '\t' {
+			return nil, nil, fmt.Errorf("front matter line %d: nested values are not supported", n)
+		}
+
+		key, raw, ok := strings.Cut(text, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, nil, fmt.Errorf("front matter line %d: expected \"key: value\"", n)
+		}
+		if _, dup := fields[key]; dup {
+			return nil, nil, fmt.Errorf("front matter line %d: duplicate key %q", n, key)
+		}
+
+		value, err := parseValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, nil, fmt.Errorf("front matter line %d: %s: %w", n, key, err)
+		}
+		fields[key] = value
+	}
+}
+
+// parseJSONFrontMatter reads the JSON object opening src. The body starts on
+// the line after the object's closing brace.
+func parseJSONFrontMatter(src []byte) (Fields, []byte, error) {
+	var fields Fields
+	dec := json.NewDecoder(bytes.NewReader(src))
+	if err := dec.Decode(&fields); err != nil || fields == nil {
+		return nil, nil, errors.New("front matter: expected a JSON object")
+	}
+
+	body := src[dec.InputOffset():]
+	line, rest, found := bytes.Cut(body, []byte("\n"))
+	if len(bytes.TrimSpace(line)) > 0 {
+		return nil, nil, errors.New("front matter: unexpected text after the JSON object")
+	}
+	if !found {
+		rest = nil
+	}
+	return fields, rest, nil
+}
+
+func parseValue(raw string) (any, error) {
+	if strings.HasPrefix(raw, "[") {
+		if !strings.HasSuffix(raw, "]") {
+			return nil, errors.New("unterminated list")
+		}
+		inner := strings.TrimSpace(raw[1 : len(raw)-1])
+		items := []any{}
+		if inner == "" {
+			return items, nil
+		}
+		for _, part := range splitList(inner) {
+			item, err := parseScalar(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	return parseScalar(raw)
+}
+
+func parseScalar(raw string) (any, error) {
+	switch {
+	case raw == "":
+		return "", nil
+	case raw == "true":
+		return true, nil
+	case raw == "false":
+		return false, nil
+	case strings.HasPrefix(raw, `"`):
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted string %s", raw)
+		}
+		return s, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return nil, fmt.Errorf("invalid quoted string %s", raw)
+		}
+		return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'"), nil
+	}
+
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return float64(n), nil
+	}
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		return f, nil
+	}
+	return raw, nil
+}
+
+// splitList splits an inline list on commas outside quotes.
+func splitList(s string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,127 @@
+package csp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Placeholder is rendered as {{.Nonce}} and replaced with a fresh nonce each
+// time a page is served, so cached bodies can carry per-request nonces.
+const Placeholder = "__LANDING_CSP_NONCE__"
+
+// MaxReports bounds the number of violations accepted from one request.
+const MaxReports = 20
+
+// ErrMalformed is returned for report bodies that are not CSP reports.
+var ErrMalformed = errors.New("malformed CSP report")
+
+// NewNonce returns a random base64url nonce.
+func NewNonce() string {
+	b := make([]byte, 18)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Violation is one reported policy violation.
+type Violation struct {
+	Document    string
+	Directive   string
+	Blocked     string
+	Source      string
+	Line        int
+	Column      int
+	Sample      string
+	Disposition string
+}
+
+// legacyReport is the application/csp-report body sent for report-uri.
+type legacyReport struct {
+	Report *struct {
+		DocumentURI        string `json:"document-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		BlockedURI         string `json:"blocked-uri"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+		ColumnNumber       int    `json:"column-number"`
+		ScriptSample       string `json:"script-sample"`
+		Disposition        string `json:"disposition"`
+	} `json:"csp-report"`
+}
+
+// reportingEntry is one element of an application/reports+json body sent
+// for report-to.
+type reportingEntry struct {
+	Type string `json:"type"`
+	Body struct {
+		DocumentURL        string `json:"documentURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		BlockedURL         string `json:"blockedURL"`
+		SourceFile         string `json:"sourceFile"`
+		LineNumber         int    `json:"lineNumber"`
+		ColumnNumber       int    `json:"columnNumber"`
+		Sample             string `json:"sample"`
+		Disposition        string `json:"disposition"`
+	} `json:"body"`
+}
+
+// ParseReports decodes a report-uri document or a Reporting API batch.
+// Non-CSP entries in a batch are skipped and at most MaxReports violations
+// are returned.
+func ParseReports(body []byte) ([]Violation, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, ErrMalformed
+	}
+
+	if body[0] == '[' {
+		var entries []reportingEntry
+		if err := json.Unmarshal(body, &entries); err != nil {
+			return nil, ErrMalformed
+		}
+		var out []Violation
+		for _, e := range entries {
+			if e.Type != "csp-violation" {
+				continue
+			}
+			out = append(out, Violation{
+				Document:    e.Body.DocumentURL,
+				Directive:   e.Body.EffectiveDirective,
+				Blocked:     e.Body.BlockedURL,
+				Source:      e.Body.SourceFile,
+				Line:        e.Body.LineNumber,
+				Column:      e.Body.ColumnNumber,
+				Sample:      e.Body.Sample,
+				Disposition: e.Body.Disposition,
+			})
+			if len(out) == MaxReports {
+				break
+			}
+		}
+		return out, nil
+	}
+
+	var legacy legacyReport
+	if err := json.Unmarshal(body, &legacy); err != nil || legacy.Report == nil {
+		return nil, ErrMalformed
+	}
+	r := legacy.Report
+	directive := r.EffectiveDirective
+	if directive == "" {
+		directive, _, _ = strings.Cut(r.ViolatedDirective, " ")
+	}
+	return []Violation{{
+		Document:    r.DocumentURI,
+		Directive:   directive,
+		Blocked:     r.BlockedURI,
+		Source:      r.SourceFile,
+		Line:        r.LineNumber,
+		Column:      r.ColumnNumber,
+		Sample:      r.ScriptSample,
+		Disposition: r.Disposition,
+	}}, nil
+}
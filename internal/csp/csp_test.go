@@ -0,0 +1,42 @@
+package csp
+
+import (
+	"testing"
+)
+
+func TestParseReports(t *testing.T) {
+	legacy := []byte(`{"csp-report":{"document-uri":"https://example.test/","violated-directive":"script-src-elem 'self'","blocked-uri":"inline","source-file":"https://example.test/","line-number":12,"disposition":"report"}}`)
+	got, err := ParseReports(legacy)
+	if err != nil {
+		t.Fatalf("legacy report: %v", err)
+	}
+	want := Violation{Document: "https://example.test/", Directive: "script-src-elem", Blocked: "inline", Source: "https://example.test/", Line: 12, Disposition: "report"}
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("legacy report: got %+v", got)
+	}
+
+	batch := []byte(`[
+		{"type":"deprecation","body":{}},
+		{"type":"csp-violation","body":{"documentURL":"https://example.test/a","effectiveDirective":"img-src","blockedURL":"https://cdn.example/x.png","disposition":"enforce"}}
+	]`)
+	got, err = ParseReports(batch)
+	if err != nil {
+		t.Fatalf("reporting batch: %v", err)
+	}
+	if len(got) != 1 || got[0].Directive != "img-src" || got[0].Blocked != "https://cdn.example/x.png" {
+		t.Fatalf("reporting batch: got %+v", got)
+	}
+
+	for _, body := range []string{"", "nope", `{"other":{}}`, `[{"type":`} {
+		if _, err := ParseReports([]byte(body)); err != ErrMalformed {
+			t.Fatalf("%q: expected ErrMalformed, got %v", body, err)
+		}
+	}
+}
+
+func TestNewNonce(t *testing.T) {
+	a, b := NewNonce(), NewNonce()
+	if a == b || len(a) != 24 {
+		t.Fatalf("unexpected nonces %q %q", a, b)
+	}
+}
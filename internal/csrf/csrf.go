@@ -0,0 +1,160 @@
+package csrf
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+const (
+	// FieldName is the form parameter carrying the token.
+	FieldName = "_csrf"
+	// HeaderName carries the token for script-driven submissions.
+	HeaderName = "X-CSRF-Token"
+	// CookieName holds the per-browser nonce tokens are bound to.
+	CookieName = "landing_csrf"
+	// Placeholder is rendered by the csrfToken template function and
+	// replaced with a fresh token each time the page is served.
+	Placeholder = "__LANDING_CSRF_TOKEN__"
+)
+
+var (
+	// ErrCrossOrigin is returned for browser requests from another origin
+	// that is not in the allowlist.
+	ErrCrossOrigin = errors.New("cross-origin request rejected")
+	// ErrToken is returned for missing, forged or expired tokens.
+	ErrToken = errors.New("invalid CSRF token")
+)
+
+var (
+	processKeyOnce sync.Once
+	processKey     []byte
+)
+
+// Protector checks request origins and issues and verifies stateless tokens.
+// A token is the expiry time signed together with a random nonce kept in a
+// cookie, so a page from another site can neither read nor forge it.
+type Protector struct {
+	key     []byte
+	maxAge  time.Duration
+	tokens  bool
+	base    string
+	origins map[string]struct{}
+}
+
+// New returns a Protector for cfg. baseURL is the site's own origin. An empty
+// secret uses a random key shared by the whole process.
+func New(cfg config.CSRF, baseURL string) *Protector {
+	key := []byte(cfg.Secret)
+	if len(key) == 0 {
+		processKeyOnce.Do(func() {
+			processKey = make([]byte, 32)
+			_, _ = rand.Read(processKey)
+		})
+		key = processKey
+	}
+
+	maxAge := time.Duration(cfg.MaxAgeMinutes) * time.Minute
+	if maxAge <= 0 {
+		maxAge = config.DefaultCSRFMaxAge * time.Minute
+	}
+
+	p := &Protector{
+		key:     key,
+		maxAge:  maxAge,
+		tokens:  cfg.Tokens,
+		origins: make(map[string]struct{}, len(cfg.AllowedOrigins)),
+	}
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		p.base = strings.ToLower(u.Scheme + "://" + u.Host)
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		p.origins[strings.ToLower(origin)] = struct{}{}
+	}
+	return p
+}
+
+// RequireTokens reports whether same-site submissions must carry a token.
+func (p *Protector) RequireTokens() bool {
+	return p.tokens
+}
+
+// MaxAge returns the token lifetime.
+func (p *Protector) MaxAge() time.Duration {
+	return p.maxAge
+}
+
+// CheckOrigin validates the browser-supplied Sec-Fetch-Site and Origin
+// headers. It reports partner when the request comes from an allowlisted
+// origin; such requests cannot carry a token because the nonce cookie is
+// not sent cross-site. Requests without either header (curl, server-side
+// clients) are not browser-driven and pass.
+func (p *Protector) CheckOrigin(r *http.Request) (partner bool, err error) {
+	origin := strings.ToLower(r.Header.Get("Origin"))
+	if _, ok := p.origins[origin]; ok && origin != "" {
+		return true, nil
+	}
+
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "same-origin", "none":
+		return false, nil
+	case "":
+	default:
+		return false, ErrCrossOrigin
+	}
+
+	if origin == "" {
+		return false, nil
+	}
+	if origin == p.base {
+		return false, nil
+	}
+	if u, err := url.Parse(origin); err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host) {
+		return false, nil
+	}
+	return false, ErrCrossOrigin
+}
+
+// NewNonce returns a random cookie value.
+func NewNonce() string {
+	b := make([]byte, 18)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Token returns a token for nonce that expires MaxAge after now.
+func (p *Protector) Token(nonce string, now time.Time) string {
+	expiry := strconv.FormatInt(now.Add(p.maxAge).Unix(), 10)
+	return expiry + "." + p.sign(expiry, nonce)
+}
+
+// Verify checks that token was issued for nonce and has not expired.
+func (p *Protector) Verify(token, nonce string, now time.Time) error {
+	expiry, sig, ok := strings.Cut(token, ".")
+	if !ok || nonce == "" || !hmac.Equal([]byte(sig), []byte(p.sign(expiry, nonce))) {
+		return ErrToken
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || now.After(time.Unix(unix, 0)) {
+		return ErrToken
+	}
+	return nil
+}
+
+func (p *Protector) sign(expiry, nonce string) string {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(expiry))
+	mac.Write([]byte{0})
+	mac.Write([]byte(nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
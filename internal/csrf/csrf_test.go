@@ -0,0 +1,63 @@
+package csrf
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	p := New(config.CSRF{AllowedOrigins: []string{"https://partner.example"}}, "https://example.com/")
+
+	cases := []struct {
+		name    string
+		headers map[string]string
+		partner bool
+		ok      bool
+	}{
+		{"no headers", nil, false, true},
+		{"same origin fetch", map[string]string{"Sec-Fetch-Site": "same-origin", "Origin": "https://example.com"}, false, true},
+		{"cross site fetch", map[string]string{"Sec-Fetch-Site": "cross-site", "Origin": "https://evil.example"}, false, false},
+		{"same site subdomain", map[string]string{"Sec-Fetch-Site": "same-site", "Origin": "https://blog.example.com"}, false, false},
+		{"base origin", map[string]string{"Origin": "https://EXAMPLE.com"}, false, true},
+		{"request host", map[string]string{"Origin": "http://internal:8080"}, false, true},
+		{"other origin", map[string]string{"Origin": "https://evil.example"}, false, false},
+		{"null origin", map[string]string{"Origin": "null"}, false, false},
+		{"partner", map[string]string{"Sec-Fetch-Site": "cross-site", "Origin": "https://partner.example"}, true, true},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest("POST", "http://internal:8080/contact", nil)
+		for k, v := range tc.headers {
+			r.Header.Set(k, v)
+		}
+		partner, err := p.CheckOrigin(r)
+		if partner != tc.partner || (err == nil) != tc.ok {
+			t.Fatalf("%s: got partner=%v err=%v", tc.name, partner, err)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	p := New(config.CSRF{Secret: "secret", MaxAgeMinutes: 60}, "https://example.com")
+	token := p.Token("nonce", now)
+
+	if err := p.Verify(token, "nonce", now.Add(59*time.Minute)); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if err := p.Verify(token, "nonce", now.Add(61*time.Minute)); err != ErrToken {
+		t.Fatalf("expected expired token to fail, got %v", err)
+	}
+	if err := p.Verify(token, "other", now); err != ErrToken {
+		t.Fatalf("expected token bound to another nonce to fail, got %v", err)
+	}
+	if err := New(config.CSRF{Secret: "other"}, "").Verify(token, "nonce", now); err != ErrToken {
+		t.Fatalf("expected token signed with another key to fail, got %v", err)
+	}
+	if err := p.Verify("", "nonce", now); err != ErrToken {
+		t.Fatalf("expected empty token to fail, got %v", err)
+	}
+}
@@ -9,6 +9,32 @@ import (
 )
 
 const (
+	default403Source = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>403 - Forbidden</title>
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <meta name="robots" content="noindex">
+  <link rel="stylesheet" href="{{asset "/static/app.css"}}">
+</head>
+<body class="w-full h-screen">
+  <div class="bg-gradient-to-r from-slate-200 to-gray-200 dark:from-gray-800 dark:to-gray-900 text-black dark:text-white">
+    <div class="flex items-center justify-center min-h-screen px-2">
+      <div class="text-center">
+        <h1 class="text-9xl font-bold">403</h1>
+        <p class="text-2xl font-medium mt-4">Request not allowed</p>
+        <p class="mt-4 mb-8">The form could not be submitted. Reload the page and try again.</p>
+        <a href="/"
+          class="px-6 py-3 bg-white font-bold rounded-full hover:bg-purple-100 transition duration-300 ease-in-out dark:bg-gray-700 dark:hover:bg-gray-600 dark:text-white">
+          Go Home
+        </a>
+      </div>
+    </div>
+  </div>
+</body>
+</html>
+`
 	default404Source = `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -16,7 +42,7 @@ const (
   <title>404 - Page not found</title>
   <meta name="viewport" content="width=device-width, initial-scale=1">
   <meta name="robots" content="noindex">
-  <link rel="stylesheet" href="/static/app.css">
+  <link rel="stylesheet" href="{{asset "/static/app.css"}}">
 </head>
 <body class="w-full h-screen">
   <div class="bg-gradient-to-r from-slate-200 to-gray-200 dark:from-gray-800 dark:to-gray-900 text-black dark:text-white">
@@ -42,7 +68,7 @@ const (
   <title>500 - Internal Server Error</title>
   <meta name="viewport" content="width=device-width, initial-scale=1">
   <meta name="robots" content="noindex">
-  <link rel="stylesheet" href="/static/app.css">
+  <link rel="stylesheet" href="{{asset "/static/app.css"}}">
 </head>
 <body class="w-full h-screen">
   <div class="bg-gradient-to-r from-slate-200 to-gray-200 dark:from-gray-800 dark:to-gray-900 text-black dark:text-white">
@@ -57,24 +83,64 @@ const (
 `
 )
 
-var (
-	default404Template = parseTemplate("404.html", default404Source)
-	default500Template = parseTemplate("500.html", default500Source)
-)
+// Templates renders the embedded error pages with a template function set,
+// so the defaults can use the same helpers as pages under web/pages.
+type Templates struct {
+	forbidden   *template.Template
+	notFound    *template.Template
+	serverError *template.Template
+}
+
+// New parses the embedded error pages with funcs. A nil map uses the
+// standard function set with no base URL or manifest.
+func New(funcs template.FuncMap) *Templates {
+	if funcs == nil {
+		funcs = pages.Funcs(pages.FuncOptions{})
+	}
+
+	return &Templates{
+		forbidden:   parseTemplate("403.html", default403Source, funcs),
+		notFound:    parseTemplate("404.html", default404Source, funcs),
+		serverError: parseTemplate("500.html", default500Source, funcs),
+	}
+}
+
+var defaults = New(nil)
 
 const (
+	fallback403 = `<!DOCTYPE html><html lang="en"><head><meta charset="utf-8"><title>Forbidden</title><meta name="robots" content="noindex"></head><body><h1>403 Forbidden</h1><p>The request was not allowed.</p></body></html>`
 	fallback404 = `<!DOCTYPE html><html lang="en"><head><meta charset="utf-8"><title>Page Not Found</title><meta name="robots" content="noindex"></head><body><h1>404 Not Found</h1><p>The requested page could not be found.</p></body></html>`
 	fallback500 = `<!DOCTYPE html><html lang="en"><head><meta charset="utf-8"><title>Server Error</title><meta name="robots" content="noindex"></head><body><h1>500 Internal Server Error</h1><p>Something went wrong.</p></body></html>`
 )
 
+// Render403 renders the embedded 403 template using the provided page data.
+func (t *Templates) Render403(data pages.PageData) []byte {
+	return renderTemplate(t.forbidden, data, fallback403)
+}
+
+// Render404 renders the embedded 404 template using the provided page data.
+func (t *Templates) Render404(data pages.PageData) []byte {
+	return renderTemplate(t.notFound, data, fallback404)
+}
+
+// Render500 renders the embedded 500 template using the provided page data.
+func (t *Templates) Render500(data pages.PageData) []byte {
+	return renderTemplate(t.serverError, data, fallback500)
+}
+
+// Default403 renders the embedded 403 template using the provided page data.
+func Default403(data pages.PageData) []byte {
+	return defaults.Render403(data)
+}
+
 // Default404 renders the embedded 404 template using the provided page data.
 func Default404(data pages.PageData) []byte {
-	return renderTemplate(default404Template, data, fallback404)
+	return defaults.Render404(data)
 }
 
 // Default500 renders the embedded 500 template using the provided page data.
 func Default500(data pages.PageData) []byte {
-	return renderTemplate(default500Template, data, fallback500)
+	return defaults.Render500(data)
 }
 
 func renderTemplate(tmpl *template.Template, data pages.PageData, fallback string) []byte {
@@ -90,12 +156,13 @@ func renderTemplate(tmpl *template.Template, data pages.PageData, fallback strin
 	return buf.Bytes()
 }
 
-func parseTemplate(name, src string) *template.Template {
+func parseTemplate(name, src string, funcs template.FuncMap) *template.Template {
 	if strings.TrimSpace(src) == "" {
 		return nil
 	}
 
 	tmpl, err := template.New(name).
+		Funcs(funcs).
 		Option("missingkey=zero").
 		Parse(src)
 	if err != nil {
@@ -4,7 +4,11 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+
+	"github.com/elchemista/LandingGo/internal/assets"
+	"github.com/elchemista/LandingGo/internal/pages"
 )
 
 func TestDefaultTemplatesMirrorWebPages(t *testing.T) {
@@ -20,6 +24,7 @@ func TestDefaultTemplatesMirrorWebPages(t *testing.T) {
 		source   string
 		filename string
 	}{
+		{name: "403", source: default403Source, filename: "403.html"},
 		{name: "404", source: default404Source, filename: "404.html"},
 		{name: "500", source: default500Source, filename: "500.html"},
 	}
@@ -37,3 +42,21 @@ func TestDefaultTemplatesMirrorWebPages(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplatesUseFuncs(t *testing.T) {
+	funcs := pages.Funcs(pages.FuncOptions{
+		Manifest: &assets.Manifest{
+			Files:        map[string]assets.ManifestEntry{"static/app.3f9a1c2b.css": {Original: "static/app.css"}},
+			Fingerprints: map[string]string{"static/app.css": "static/app.3f9a1c2b.css"},
+		},
+	})
+
+	body := string(New(funcs).Render404(pages.PageData{}))
+	if !strings.Contains(body, `href="/static/app.3f9a1c2b.css"`) {
+		t.Fatalf("404 page did not resolve asset URL: %s", body)
+	}
+
+	if body := string(Default500(pages.PageData{})); !strings.Contains(body, `href="/static/app.css"`) {
+		t.Fatalf("default 500 page should fall back to the plain asset path: %s", body)
+	}
+}
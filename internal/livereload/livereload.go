@@ -0,0 +1,173 @@
+package livereload
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Path is the endpoint browsers subscribe to for reload events.
+const Path = "/__livereload"
+
+const keepAliveInterval = 15 * time.Second
+
+// Snippet is injected into rendered HTML in development mode. It reloads the
+// page whenever the server publishes a reload event.
+const Snippet = `<script>(function(){if(!window.EventSource)return;var es=new EventSource("` + Path + `");es.addEventListener("reload",function(){es.close();location.reload();});})();</script>`
+
+// Hub fans reload events out to connected browsers over Server-Sent Events.
+type Hub struct {
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+	closed  bool
+	done    chan struct{}
+}
+
+// New constructs an empty Hub.
+func New() *Hub {
+	return &Hub{
+		clients: make(map[chan struct{}]struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Reload notifies every connected browser to reload the page.
+func (h *Hub) Reload() {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close disconnects every subscriber. Subsequent subscriptions return
+// immediately.
+func (h *Hub) Close() {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	close(h.done)
+}
+
+// ServeHTTP streams reload events until the client disconnects or the hub is
+// closed.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ch, ok := h.subscribe()
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	defer h.unsubscribe(ch)
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-store, max-age=0")
+	header.Set("Connection", "keep-alive")
+	header.Del("Content-Length")
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.done:
+			// A reload published just before Close must still reach the
+			// browser; select would otherwise pick either case at random.
+			select {
+			case <-ch:
+				_, _ = fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+				flusher.Flush()
+			default:
+			}
+			return
+		case <-ticker.C:
+			_, _ = fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case <-ch:
+			_, _ = fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func (h *Hub) subscribe() (chan struct{}, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, false
+	}
+
+	ch := make(chan struct{}, 1)
+	h.clients[ch] = struct{}{}
+	return ch, true
+}
+
+func (h *Hub) unsubscribe(ch chan struct{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients, ch)
+}
+
+// Inject inserts the reload snippet before the closing body tag, or appends it
+// when the document has none.
+func Inject(body []byte) []byte {
+	return inject(body, Snippet)
+}
+
+// InjectNonce is Inject for pages under a Content-Security-Policy: the
+// snippet carries nonce so the policy allows it.
+func InjectNonce(body []byte, nonce string) []byte {
+	return inject(body, strings.Replace(Snippet, "<script>", `<script nonce="`+nonce+`">`, 1))
+}
+
+func inject(body []byte, snippet string) []byte {
+	idx := bytes.LastIndex(bytes.ToLower(body), []byte("</body>"))
+	if idx < 0 {
+		out := make([]byte, 0, len(body)+len(snippet))
+		out = append(out, body...)
+		return append(out, snippet...)
+	}
+
+	out := make([]byte, 0, len(body)+len(snippet))
+	out = append(out, body[:idx]...)
+	out = append(out, snippet...)
+	return append(out, body[idx:]...)
+}
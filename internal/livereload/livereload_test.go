@@ -0,0 +1,53 @@
+package livereload
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// gatedRecorder blocks the first Flush until released, so a test can queue
+// events while the handler is not yet waiting on them.
+type gatedRecorder struct {
+	*httptest.ResponseRecorder
+	flushed chan struct{}
+	release chan struct{}
+}
+
+func (g *gatedRecorder) Flush() {
+	if g.flushed != nil {
+		close(g.flushed)
+		g.flushed = nil
+		<-g.release
+	}
+	g.ResponseRecorder.Flush()
+}
+
+func TestCloseDeliversPendingReload(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		hub := New()
+		rec := &gatedRecorder{
+			ResponseRecorder: httptest.NewRecorder(),
+			flushed:          make(chan struct{}),
+			release:          make(chan struct{}),
+		}
+		flushed := rec.flushed
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			hub.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+		}()
+
+		<-flushed
+		hub.Reload()
+		hub.Close()
+		close(rec.release)
+		<-done
+
+		if body := rec.Body.String(); !strings.Contains(body, "event: reload") {
+			t.Fatalf("run %d: reload published before Close was dropped: %q", i, body)
+		}
+	}
+}
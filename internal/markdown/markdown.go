@@ -0,0 +1,374 @@
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Render converts a CommonMark-flavoured subset of Markdown to HTML. It
+// supports ATX headings, paragraphs, fenced code blocks, block quotes, flat
+// ordered and unordered lists, thematic breaks, GitHub-style pipe tables, and
+// the inline forms for code, emphasis, strong emphasis, links and images. Raw
+// HTML in the source is escaped rather than passed through.
+func Render(src []byte) []byte {
+	lines := strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n")
+
+	var buf bytes.Buffer
+	renderBlocks(&buf, lines)
+	return buf.Bytes()
+}
+
+// RenderInline renders src and, when the result is a single paragraph, strips
+// the surrounding <p> element so it can be embedded in running text.
+func RenderInline(src []byte) []byte {
+	out := bytes.TrimSpace(Render(src))
+	if bytes.HasPrefix(out, []byte("<p>")) && bytes.HasSuffix(out, []byte("</p>")) &&
+		bytes.Count(out, []byte("<p>")) == 1 {
+		out = out[len("<p>") : len(out)-len("</p>")]
+	}
+	return out
+}
+
+var (
+	headingPattern   = regexp.MustCompile(`^(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	unorderedPattern = regexp.MustCompile(`^[ ]{0,3}[-*+][ \t]+(.*)$`)
+	orderedPattern   = regexp.MustCompile(`^[ ]{0,3}(\d{1,9})[.)][ \t]+(.*)$`)
+	rulePattern      = regexp.MustCompile(`^[ ]{0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	delimCellPattern = regexp.MustCompile(`^:?-+:?$`)
+)
+
+func renderBlocks(buf *bytes.Buffer, lines []string) {
+	var para []string
+
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		buf.WriteString("<p>")
+		buf.WriteString(renderInline(strings.Join(para, "\n")))
+		buf.WriteString("</p>\n")
+		para = para[:0]
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			fence := trimmed[:3]
+			lang := strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1]))
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			buf.WriteString("<pre><code")
+			if lang != "" {
+				buf.WriteString(` class="language-`)
+				buf.WriteString(html.EscapeString(strings.Fields(lang)[0]))
+				buf.WriteString(`"`)
+			}
+			buf.WriteString(">")
+			for _, c := range code {
+				buf.WriteString(html.EscapeString(c))
+				buf.WriteString("\n")
+			}
+			buf.WriteString("</code></pre>\n")
+
+		case headingPattern.MatchString(trimmed):
+			flush()
+			m := headingPattern.FindStringSubmatch(trimmed)
+			level := strconv.Itoa(len(m[1]))
+			buf.WriteString("<h" + level + ">")
+			buf.WriteString(renderInline(m[2]))
+			buf.WriteString("</h" + level + ">\n")
+
+		case rulePattern.MatchString(line):
+			flush()
+			buf.WriteString("<hr>\n")
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			var quoted []string
+			for ; i < len(lines); i++ {
+				t := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(t, ">") {
+					i--
+					break
+				}
+				t = strings.TrimPrefix(t, ">")
+				quoted = append(quoted, strings.TrimPrefix(t, " "))
+			}
+			buf.WriteString("<blockquote>\n")
+			renderBlocks(buf, quoted)
+			buf.WriteString("</blockquote>\n")
+
+		case unorderedPattern.MatchString(line):
+			flush()
+			i = renderList(buf, lines, i, false)
+
+		case orderedPattern.MatchString(line):
+			flush()
+			i = renderList(buf, lines, i, true)
+
+		case i+1 < len(lines) && tableAligns(line, lines[i+1]) != nil:
+			flush()
+			i = renderTable(buf, lines, i)
+
+		default:
+			para = append(para, trimmed)
+		}
+	}
+
+	flush()
+}
+
+// renderList writes the list starting at lines[start] and returns the index
+// of its last line. Continuation lines are folded into the preceding item.
+func renderList(buf *bytes.Buffer, lines []string, start int, ordered bool) int {
+	pattern, tag := unorderedPattern, "ul"
+	if ordered {
+		pattern, tag = orderedPattern, "ol"
+	}
+
+	var items []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if m := pattern.FindStringSubmatch(line); m != nil {
+			if ordered && len(items) == 0 {
+				if n, err := strconv.Atoi(m[1]); err == nil && n != 1 {
+					tag = `ol start="` + strconv.Itoa(n) + `"`
+				}
+			}
+			items = append(items, m[len(m)-1])
+			continue
+		}
+		if strings.TrimSpace(line) != "" && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(items) > 0 {
+			items[len(items)-1] += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		break
+	}
+
+	buf.WriteString("<" + tag + ">\n")
+	for _, item := range items {
+		buf.WriteString("<li>")
+		buf.WriteString(renderInline(item))
+		buf.WriteString("</li>\n")
+	}
+	buf.WriteString("</" + tag[:2] + ">\n")
+
+	return i - 1
+}
+
+// tableAligns returns the column alignments when header and delim open a
+// pipe table: a header row followed by a delimiter row such as |---|:-:|
+// with the same number of cells. It returns nil otherwise.
+func tableAligns(header, delim string) []string {
+	if !strings.Contains(header, "|") || !strings.Contains(delim, "|") {
+		return nil
+	}
+
+	cells := splitRow(delim)
+	if len(cells) != len(splitRow(header)) {
+		return nil
+	}
+
+	aligns := make([]string, len(cells))
+	for i, cell := range cells {
+		if !delimCellPattern.MatchString(cell) {
+			return nil
+		}
+		switch left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":"); {
+		case left && right:
+			aligns[i] = "center"
+		case right:
+			aligns[i] = "right"
+		case left:
+			aligns[i] = "left"
+		}
+	}
+	return aligns
+}
+
+// splitRow splits a table row into trimmed cells. Leading and trailing pipes
+// are optional and \| is a literal pipe.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+
+	var (
+		cells []string
+		cell  strings.Builder
+	)
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// renderTable writes the table starting at lines[start] and returns the
+// index of its last line. Body rows run until a blank line or a line without
+// a pipe; they are padded or truncated to the header's width.
+func renderTable(buf *bytes.Buffer, lines []string, start int) int {
+	aligns := tableAligns(lines[start], lines[start+1])
+
+	row := func(cells []string, tag string) {
+		buf.WriteString("<tr>\n")
+		for i, align := range aligns {
+			buf.WriteString("<" + tag)
+			if align != "" {
+				buf.WriteString(` align="` + align + `"`)
+			}
+			buf.WriteString(">")
+			if i < len(cells) {
+				buf.WriteString(renderInline(cells[i]))
+			}
+			buf.WriteString("</" + tag + ">\n")
+		}
+		buf.WriteString("</tr>\n")
+	}
+
+	buf.WriteString("<table>\n<thead>\n")
+	row(splitRow(lines[start]), "th")
+	buf.WriteString("</thead>\n")
+
+	i := start + 2
+	if i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != "" {
+		buf.WriteString("<tbody>\n")
+		for ; i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != ""; i++ {
+			row(splitRow(lines[i]), "td")
+		}
+		buf.WriteString("</tbody>\n")
+	}
+	buf.WriteString("</table>\n")
+
+	return i - 1
+}
+
+var (
+	imagePattern  = regexp.MustCompile(`!\[([^\]]*)\]\(\s*([^)\s]+)(?:\s+&#34;(.*?)&#34;)?\s*\)`)
+	linkPattern   = regexp.MustCompile(`\[([^\]]*)\]\(\s*([^)\s]+)(?:\s+&#34;(.*?)&#34;)?\s*\)`)
+	strongPattern = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	emPattern     = regexp.MustCompile(`\*([^*\s][^*]*)\*|\b_([^_\s][^_]*)_\b`)
+)
+
+// renderInline escapes text and applies inline formatting. Code spans are
+// extracted first so their contents are never formatted.
+func renderInline(text string) string {
+	var (
+		out   strings.Builder
+		spans []string
+	)
+
+	for {
+		open := strings.IndexByte(text, '`')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(text[open+1:], '`')
+		if end < 0 {
+			break
+		}
+		spans = append(spans, text[open+1:open+1+end])
+		out.WriteString(formatText(text[:open]))
+		out.WriteString("\x00" + strconv.Itoa(len(spans)-1) + "\x00")
+		text = text[open+1+end+1:]
+	}
+	out.WriteString(formatText(text))
+
+	result := out.String()
+	for i, span := range spans {
+		result = strings.Replace(result, "\x00"+strconv.Itoa(i)+"\x00", "<code>"+html.EscapeString(strings.TrimSpace(span))+"</code>", 1)
+	}
+
+	return result
+}
+
+func formatText(text string) string {
+	text = html.EscapeString(text)
+
+	// Generated tags are swapped for placeholders until emphasis is applied,
+	// so * and _ inside their attributes are left alone.
+	var tags []string
+	hold := func(tag string) string {
+		tags = append(tags, tag)
+		return "\x01" + strconv.Itoa(len(tags)-1) + "\x01"
+	}
+
+	// Images go first so their ![alt](src) form is not taken for a link.
+	text = imagePattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := imagePattern.FindStringSubmatch(m)
+		src := html.UnescapeString(parts[2])
+		if !SafeURL(src) || strings.HasPrefix(strings.ToLower(strings.TrimSpace(src)), "mailto:") {
+			return parts[1]
+		}
+		img := `<img src="` + html.EscapeString(src) + `" alt="` + parts[1] + `"`
+		if parts[3] != "" {
+			img += ` title="` + parts[3] + `"`
+		}
+		return hold(img + ">")
+	})
+
+	text = linkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := linkPattern.FindStringSubmatch(m)
+		href := html.UnescapeString(parts[2])
+		if !SafeURL(href) {
+			return parts[1]
+		}
+		link := `<a href="` + html.EscapeString(href) + `"`
+		if parts[3] != "" {
+			link += ` title="` + parts[3] + `"`
+		}
+		return hold(link+">") + parts[1] + "</a>"
+	})
+
+	text = strongPattern.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = emPattern.ReplaceAllString(text, "<em>$1$2</em>")
+
+	for i, tag := range tags {
+		text = strings.Replace(text, "\x01"+strconv.Itoa(i)+"\x01", tag, 1)
+	}
+	return text
+}
+
+// SafeURL reports whether href is acceptable as a link target. Relative URLs,
+// fragments and the http, https and mailto schemes are allowed.
+func SafeURL(href string) bool {
+	lower := strings.ToLower(strings.TrimSpace(href))
+	colon := strings.IndexByte(lower, ':')
+	if colon < 0 {
+		return true
+	}
+	if slash := strings.IndexAny(lower, "/?#"); slash >= 0 && slash < colon {
+		return true
+	}
+	switch lower[:colon] {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
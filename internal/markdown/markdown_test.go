@@ -0,0 +1,71 @@
+package markdown
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "heading and paragraph",
+			in:   "# Title #\n\nSome *soft* and **bold** text\nwith `a <b>` span.",
+			want: "<h1>Title</h1>\n<p>Some <em>soft</em> and <strong>bold</strong> text\nwith <code>a &lt;b&gt;</code> span.</p>\n",
+		},
+		{
+			name: "lists",
+			in:   "- one\n- two\n  continued\n\n3. three\n4. four",
+			want: "<ul>\n<li>one</li>\n<li>two\ncontinued</li>\n</ul>\n<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>\n",
+		},
+		{
+			name: "code fence and quote",
+			in:   "```go\nfmt.Println(\"<hi>\")\n```\n> quoted **text**\n\n---",
+			want: "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;hi&gt;&#34;)\n</code></pre>\n<blockquote>\n<p>quoted <strong>text</strong></p>\n</blockquote>\n<hr>\n",
+		},
+		{
+			name: "links",
+			in:   `[docs](/docs "Read me") and [bad](javascript:void) <script>`,
+			want: "<p><a href=\"/docs\" title=\"Read me\">docs</a> and bad &lt;script&gt;</p>\n",
+		},
+		{
+			name: "images",
+			in:   `![Hero <shot>](/static/img/hero.png "Our team") [![logo](/static/logo.svg)](/) ![x](javascript:alert)`,
+			want: "<p><img src=\"/static/img/hero.png\" alt=\"Hero &lt;shot&gt;\" title=\"Our team\"> <a href=\"/\"><img src=\"/static/logo.svg\" alt=\"logo\"></a> x</p>\n",
+		},
+		{
+			name: "emphasis markers inside URLs",
+			in:   `[x](https://e.com/?q=a*b*c "a_b_c") ![my_*shot*](/static/a_b_c.png) *after*`,
+			want: "<p><a href=\"https://e.com/?q=a*b*c\" title=\"a_b_c\">x</a> <img src=\"/static/a_b_c.png\" alt=\"my_*shot*\"> <em>after</em></p>\n",
+		},
+		{
+			name: "table",
+			in:   "Plans:\n\n| Plan | Price |  Notes |\n|:-----|------:|:------:|\n| Basic | `9\\|mo` | *new* |\n| Pro | 19\n\nAfter.",
+			want: "<p>Plans:</p>\n<table>\n<thead>\n<tr>\n<th align=\"left\">Plan</th>\n<th align=\"right\">Price</th>\n<th align=\"center\">Notes</th>\n</tr>\n</thead>\n<tbody>\n" +
+				"<tr>\n<td align=\"left\">Basic</td>\n<td align=\"right\"><code>9|mo</code></td>\n<td align=\"center\"><em>new</em></td>\n</tr>\n" +
+				"<tr>\n<td align=\"left\">Pro</td>\n<td align=\"right\">19</td>\n<td align=\"center\"></td>\n</tr>\n</tbody>\n</table>\n<p>After.</p>\n",
+		},
+		{
+			name: "pipe without delimiter row",
+			in:   "a | b\nc | d",
+			want: "<p>a | b\nc | d</p>\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := string(Render([]byte(tc.in))); got != tc.want {
+				t.Fatalf("Render mismatch:\n got %q\nwant %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRenderInline(t *testing.T) {
+	if got := string(RenderInline([]byte("Hello _world_"))); got != "Hello <em>world</em>" {
+		t.Fatalf("unexpected inline render: %q", got)
+	}
+	if got := string(RenderInline([]byte("one\n\ntwo"))); got != "<p>one</p>\n<p>two</p>" {
+		t.Fatalf("multi-paragraph input should keep paragraphs: %q", got)
+	}
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// keyClientIP is used to stash the resolved client address in the context.
+type keyClientIP struct{}
+
+// IPResolver determines the client address of a request. Forwarding headers
+// are only believed when the connection comes from a trusted proxy, and are
+// read right to left so a client cannot prepend a forged hop.
+type IPResolver struct {
+	trusted []netip.Prefix
+	fly     bool
+}
+
+// NewIPResolver returns a resolver trusting the given proxy prefixes. When
+// fly is set the Fly-Client-IP header of a trusted proxy is authoritative.
+func NewIPResolver(trusted []netip.Prefix, fly bool) *IPResolver {
+	return &IPResolver{trusted: trusted, fly: fly}
+}
+
+// Resolve returns the client address of r. In order of preference it uses
+// Fly-Client-IP, the RFC 7239 Forwarded header, X-Forwarded-For and
+// X-Real-IP, each only when the peer is a trusted proxy, and otherwise the
+// connection's remote host.
+func (res *IPResolver) Resolve(r *http.Request) string {
+	peer := remoteHost(r.RemoteAddr)
+	addr, err := netip.ParseAddr(peer)
+	if err != nil || res == nil || !res.trustedAddr(addr.Unmap()) {
+		return peer
+	}
+
+	if res.fly {
+		if ip, ok := parseIP(r.Header.Get("Fly-Client-IP")); ok {
+			return ip.String()
+		}
+	}
+	if hops := forwardedFor(r.Header.Values("Forwarded")); len(hops) > 0 {
+		return res.walk(hops, addr)
+	}
+	if hops := splitList(r.Header.Values("X-Forwarded-For")); len(hops) > 0 {
+		return res.walk(hops, addr)
+	}
+	if ip, ok := parseIP(r.Header.Get("X-Real-IP")); ok {
+		return ip.String()
+	}
+	return addr.Unmap().String()
+}
+
+// walk returns the right-most hop that is not a trusted proxy. When every
+// hop is trusted the left-most one is used; an unparsable hop ends the walk
+// at the proxy that reported it.
+func (res *IPResolver) walk(hops []string, peer netip.Addr) string {
+	client := peer.Unmap()
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip, ok := parseIP(hops[i])
+		if !ok {
+			break
+		}
+		client = ip
+		if !res.trustedAddr(ip) {
+			break
+		}
+	}
+	return client.String()
+}
+
+func (res *IPResolver) trustedAddr(addr netip.Addr) bool {
+	for _, prefix := range res.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveClientIP stores the client address resolved by res in the request
+// context, where ClientIP reads it.
+func ResolveClientIP(res *IPResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), keyClientIP{}, res.Resolve(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ClientIP returns the client address stored by ResolveClientIP, falling
+// back to the connection's remote host. Forwarding headers are never read
+// here.
+func ClientIP(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	if ip, ok := r.Context().Value(keyClientIP{}).(string); ok {
+		return ip
+	}
+	return remoteHost(r.RemoteAddr)
+}
+
+func remoteHost(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// parseIP parses a forwarded hop, which may carry a port and, for IPv6,
+// brackets.
+func parseIP(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return netip.Addr{}, false
+	}
+	if ap, err := netip.ParseAddrPort(value); err == nil {
+		return ap.Addr().Unmap(), true
+	}
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// splitList flattens comma-separated header values in order.
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// forwardedFor extracts the for= parameters of RFC 7239 Forwarded headers
+// in order. Obfuscated and "unknown" identifiers are kept so the walk stops
+// at them.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, element := range splitList(values) {
+		for _, pair := range strings.Split(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(key), "for") {
+				continue
+			}
+			hops = append(hops, strings.Trim(strings.TrimSpace(value), `"`))
+		}
+	}
+	return hops
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestIPResolver(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("fdaa::/16"),
+	}
+	res := NewIPResolver(trusted, true)
+
+	cases := []struct {
+		name   string
+		remote string
+		header http.Header
+		want   string
+	}{
+		{"untrusted peer ignores headers", "203.0.113.9:4000", http.Header{"X-Forwarded-For": {"1.2.3.4"}, "Fly-Client-IP": {"1.2.3.4"}}, "203.0.113.9"},
+		{"no headers", "10.0.0.2:4000", nil, "10.0.0.2"},
+		{"spoofed left-most hop", "10.0.0.2:4000", http.Header{"X-Forwarded-For": {"1.2.3.4, 198.51.100.7, 10.0.0.5"}}, "198.51.100.7"},
+		{"repeated headers", "10.0.0.2:4000", http.Header{"X-Forwarded-For": {"1.2.3.4", "198.51.100.7"}}, "198.51.100.7"},
+		{"all hops trusted", "10.0.0.2:4000", http.Header{"X-Forwarded-For": {"10.1.1.1, 10.0.0.5"}}, "10.1.1.1"},
+		{"garbage hop", "10.0.0.2:4000", http.Header{"X-Forwarded-For": {"1.2.3.4, nonsense"}}, "10.0.0.2"},
+		{"forwarded", "10.0.0.2:4000", http.Header{"Forwarded": {`for=1.2.3.4, for="[2001:db8::17]:4711";proto=https`}, "X-Forwarded-For": {"9.9.9.9"}}, "2001:db8::17"},
+		{"forwarded obfuscated", "10.0.0.2:4000", http.Header{"Forwarded": {"for=_hidden, for=10.0.0.7"}}, "10.0.0.7"},
+		{"fly client ip", "[fdaa::3]:4000", http.Header{"Fly-Client-IP": {"198.51.100.7"}, "X-Forwarded-For": {"1.2.3.4"}}, "198.51.100.7"},
+		{"real ip", "10.0.0.2:4000", http.Header{"X-Real-IP": {"198.51.100.7"}}, "198.51.100.7"},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.remote
+		for k, values := range tc.header {
+			for _, v := range values {
+				req.Header.Add(k, v)
+			}
+		}
+		if got := res.Resolve(req); got != tc.want {
+			t.Fatalf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	noFly := NewIPResolver(trusted, false)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.2:4000"
+	req.Header.Set("Fly-Client-IP", "1.2.3.4")
+	if got := noFly.Resolve(req); got != "10.0.0.2" {
+		t.Fatalf("Fly-Client-IP honoured without the fly preset: %q", got)
+	}
+}
+
+func TestResolveClientIPStoresAddress(t *testing.T) {
+	var got string
+	h := ResolveClientIP(NewIPResolver([]netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}, false))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = ClientIP(r) }),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if got != "198.51.100.7" {
+		t.Fatalf("unexpected client ip %q", got)
+	}
+
+	bare := httptest.NewRequest(http.MethodGet, "/", nil)
+	bare.RemoteAddr = "203.0.113.9:4000"
+	bare.Header.Set("X-Forwarded-For", "1.2.3.4")
+	if ip := ClientIP(bare); ip != "203.0.113.9" {
+		t.Fatalf("ClientIP without resolver read headers: %q", ip)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -100,7 +101,7 @@ func Logging(logger *slog.Logger) func(http.Handler) http.Handler {
 			next.ServeHTTP(recorder, r)
 
 			logger.Info("request completed",
-				"ip", clientIP(r),
+				"ip", ClientIP(r),
 				"method", r.Method,
 				"path", r.URL.Path,
 				"status", recorder.status,
@@ -126,7 +127,7 @@ func Gzip(level int) func(http.Handler) http.Handler {
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.Method == http.MethodHead || !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			if r.Method == http.MethodHead || !AcceptsGzip(r) {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -146,6 +147,37 @@ func Gzip(level int) func(http.Handler) http.Handler {
 	}
 }
 
+// AcceptsGzip reports whether the request's Accept-Encoding admits gzip with a
+// non-zero quality, either explicitly or through a wildcard.
+func AcceptsGzip(r *http.Request) bool {
+	accepted := false
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "x-gzip" && coding != "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, val, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(strings.TrimSpace(key), "q") {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		if coding == "*" {
+			accepted = q > 0
+			continue
+		}
+		// An explicit gzip entry overrides any wildcard.
+		return q > 0
+	}
+	return accepted
+}
+
 // responseRecorder captures status codes for logging.
 type responseRecorder struct {
 	http.ResponseWriter
@@ -195,24 +227,6 @@ func randomID() string {
 	return hex.EncodeToString(b[:])
 }
 
-func clientIP(r *http.Request) string {
-	if r == nil {
-		return ""
-	}
-	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
-		parts := strings.Split(fwd, ",")
-		return strings.TrimSpace(parts[0])
-	}
-	if real := r.Header.Get("X-Real-IP"); real != "" {
-		return strings.TrimSpace(real)
-	}
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
-	}
-	return host
-}
-
 type gzipResponseWriter struct {
 	http.ResponseWriter
 	pool        *sync.Pool
@@ -228,39 +242,95 @@ func (g *gzipResponseWriter) ensureWriter() {
 	if g.writer != nil {
 		return
 	}
+
+	header := g.Header()
+
+	// Responses that are already encoded (e.g. precompressed assets) or whose
+	// media type is compressed by nature pass through untouched.
+	if header.Get("Content-Encoding") != "" || !compressibleType(header.Get("Content-Type")) {
+		g.compress = false
+		return
+	}
+
 	gw := g.pool.Get().(*gzip.Writer)
 	gw.Reset(g.ResponseWriter)
 	g.writer = gw
-	header := g.Header()
 	header.Del("Content-Length")
 	header.Set("Content-Encoding", "gzip")
-	header.Add("Vary", "Accept-Encoding")
+	if !headerHasToken(header, "Vary", "Accept-Encoding") {
+		header.Add("Vary", "Accept-Encoding")
+	}
 }
 
 func (g *gzipResponseWriter) WriteHeader(code int) {
 	if code >= 400 {
 		g.DisableCompression()
+	} else if bodyAllowed(code) {
+		// Headers are flushed with the status line, so the encoding decision
+		// has to be made now rather than on the first Write.
+		g.ensureWriter()
 	}
 	g.wroteHeader = true
 	g.ResponseWriter.WriteHeader(code)
 }
 
 func (g *gzipResponseWriter) Write(p []byte) (int, error) {
-	if !g.compress {
+	if g.compress && g.writer == nil && !g.wroteHeader {
+		g.ensureWriter()
+	}
+	if !g.compress || g.writer == nil {
 		if !g.wroteHeader {
 			g.WriteHeader(http.StatusOK)
 		}
 		return g.ResponseWriter.Write(p)
 	}
-	if g.writer == nil {
-		g.ensureWriter()
-	}
 	if !g.wroteHeader {
 		g.WriteHeader(http.StatusOK)
 	}
 	return g.writer.Write(p)
 }
 
+func bodyAllowed(code int) bool {
+	return code >= http.StatusOK && code != http.StatusNoContent && code != http.StatusNotModified
+}
+
+// compressibleType reports whether a response of the given media type benefits
+// from gzip. Images, audio, video, archives and woff fonts are already
+// compressed; an empty type is assumed to be HTML.
+func compressibleType(contentType string) bool {
+	mt := strings.ToLower(strings.TrimSpace(contentType))
+	if idx := strings.IndexByte(mt, ';'); idx >= 0 {
+		mt = strings.TrimSpace(mt[:idx])
+	}
+
+	switch {
+	case mt == "":
+		return true
+	case mt == "image/svg+xml", mt == "image/x-icon":
+		return true
+	case strings.HasPrefix(mt, "image/"), strings.HasPrefix(mt, "video/"), strings.HasPrefix(mt, "audio/"):
+		return false
+	}
+
+	switch mt {
+	case "font/woff", "font/woff2", "application/gzip", "application/zip", "application/pdf", "application/octet-stream":
+		return false
+	default:
+		return true
+	}
+}
+
+func headerHasToken(header http.Header, key, token string) bool {
+	for _, value := range header.Values(key) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (g *gzipResponseWriter) Close() {
 	if g.writer == nil {
 		return
@@ -0,0 +1,494 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	mathrand "math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/contact"
+)
+
+// File names inside the outbox directory.
+const (
+	QueueFile      = "outbox.jsonl"
+	DeadLetterFile = "dead-letter.jsonl"
+)
+
+// Defaults applied when Options leaves a field zero.
+const (
+	DefaultMaxAttempts = 8
+	DefaultBaseDelay   = 30 * time.Second
+	DefaultMaxDelay    = time.Hour
+	DefaultSendTimeout = 30 * time.Second
+)
+
+// Record operations written to the queue file.
+const (
+	opQueued    = "queued"
+	opRetry     = "retry"
+	opDelivered = "delivered"
+	opDead      = "dead"
+)
+
+// ErrClosed is returned by Enqueue after the outbox has been drained.
+var ErrClosed = errors.New("outbox closed")
+
+// Options tunes delivery retries.
+type Options struct {
+	// MaxAttempts is the number of deliveries tried before a message is
+	// moved to the dead-letter file.
+	MaxAttempts int
+	// BaseDelay is the wait after the first failure; it doubles with each
+	// further failure up to MaxDelay. Actual waits are jittered between half
+	// and the full delay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// SendTimeout bounds a single delivery attempt.
+	SendTimeout time.Duration
+	Logger      *slog.Logger
+}
+
+// Entry is a queued message and its delivery state.
+type Entry struct {
+	ID        string          `json:"id"`
+	Message   contact.Message `json:"message"`
+	QueuedAt  time.Time       `json:"queued_at"`
+	Attempts  int             `json:"attempts"`
+	NextAt    time.Time       `json:"next_at"`
+	LastError string          `json:"last_error,omitempty"`
+}
+
+type record struct {
+	Op string `json:"op"`
+	Entry
+}
+
+// Outbox is a durable queue of contact messages. Each state change is
+// appended to a JSONL file and synced before it is acknowledged, so queued
+// messages survive crashes and restarts.
+type Outbox struct {
+	dir    string
+	sender func(form string) contact.Sender
+	opts   Options
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	file    *os.File
+	pending map[string]*Entry
+	closed  bool
+
+	wake    chan struct{}
+	running sync.Mutex
+}
+
+// Open loads or creates the outbox in dir. sender is called with the
+// message's form name before each delivery attempt so messages always go out
+// through the current configuration. Messages left over from a previous run
+// are resumed.
+func Open(dir string, sender func(form string) contact.Sender, opts Options) (*Outbox, error) {
+	if dir == "" {
+		return nil, errors.New("outbox directory is required")
+	}
+	if sender == nil {
+		return nil, errors.New("outbox sender is required")
+	}
+
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = DefaultBaseDelay
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = DefaultMaxDelay
+	}
+	if opts.SendTimeout <= 0 {
+		opts.SendTimeout = DefaultSendTimeout
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create outbox directory: %w", err)
+	}
+
+	o := &Outbox{
+		dir:     dir,
+		sender:  sender,
+		opts:    opts,
+		logger:  logger,
+		pending: make(map[string]*Entry),
+		wake:    make(chan struct{}, 1),
+	}
+
+	if err := o.load(); err != nil {
+		return nil, err
+	}
+	if err := o.compact(); err != nil {
+		return nil, err
+	}
+
+	if n := len(o.pending); n > 0 {
+		logger.Info("outbox resumed", "pending", n)
+	}
+
+	return o, nil
+}
+
+// Enqueue durably records msg for delivery. It returns once the message has
+// been synced to disk.
+func (o *Outbox) Enqueue(msg contact.Message) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	entry := &Entry{ID: id, Message: msg, QueuedAt: now, NextAt: now}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return ErrClosed
+	}
+	if err := o.append(opQueued, entry); err != nil {
+		return err
+	}
+	o.pending[id] = entry
+	o.notify()
+
+	return nil
+}
+
+// Pending returns the number of messages awaiting delivery.
+func (o *Outbox) Pending() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.pending)
+}
+
+// Run delivers due messages until ctx is cancelled.
+func (o *Outbox) Run(ctx context.Context) {
+	o.running.Lock()
+	defer o.running.Unlock()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.wake:
+		case <-timer.C:
+		}
+
+		next := o.deliverDue(ctx, time.Now())
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if !next.IsZero() {
+			timer.Reset(time.Until(next))
+		}
+	}
+}
+
+// Drain waits for Run to return, tries every pending message once regardless
+// of its backoff, and closes the queue file. Messages that still fail remain
+// queued for the next start. Cancel the context passed to Run first.
+func (o *Outbox) Drain(ctx context.Context) error {
+	o.running.Lock()
+	defer o.running.Unlock()
+
+	o.mu.Lock()
+	entries := o.sortedPending()
+	o.mu.Unlock()
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		o.attempt(ctx, entry)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.closed = true
+	if n := len(o.pending); n > 0 {
+		o.logger.Warn("outbox drained with undelivered messages", "pending", n)
+	}
+	if o.file == nil {
+		return nil
+	}
+	err := o.file.Close()
+	o.file = nil
+	return err
+}
+
+// deliverDue attempts every message whose backoff has elapsed and returns
+// when the next one becomes due, or zero when nothing is queued.
+func (o *Outbox) deliverDue(ctx context.Context, now time.Time) time.Time {
+	o.mu.Lock()
+	entries := o.sortedPending()
+	o.mu.Unlock()
+
+	var next time.Time
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return time.Time{}
+		}
+		if entry.NextAt.After(now) {
+			if next.IsZero() || entry.NextAt.Before(next) {
+				next = entry.NextAt
+			}
+			continue
+		}
+		if retryAt, ok := o.attempt(ctx, entry); ok && (next.IsZero() || retryAt.Before(next)) {
+			next = retryAt
+		}
+	}
+
+	return next
+}
+
+// attempt delivers entry once and records the outcome. It returns the retry
+// time when the message stays queued.
+func (o *Outbox) attempt(ctx context.Context, entry Entry) (time.Time, bool) {
+	err := errors.New("contact delivery disabled")
+	if sender := o.sender(entry.Message.Form); sender != nil && sender.Enabled() {
+		sendCtx, cancel := context.WithTimeout(ctx, o.opts.SendTimeout)
+		err = sender.Send(sendCtx, entry.Message)
+		cancel()
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.pending[entry.ID]; !ok || o.file == nil {
+		return time.Time{}, false
+	}
+
+	entry.Attempts++
+
+	if err == nil {
+		o.logger.Info("outbox delivered", "id", entry.ID, "attempts", entry.Attempts)
+		o.finish(opDelivered, &entry)
+		return time.Time{}, false
+	}
+
+	entry.LastError = err.Error()
+
+	if entry.Attempts >= o.opts.MaxAttempts {
+		o.logger.Error("outbox gave up", "id", entry.ID, "attempts", entry.Attempts, "error", err)
+		if derr := o.deadLetter(&entry); derr != nil {
+			o.logger.Error("outbox dead letter", "id", entry.ID, "error", derr)
+			return time.Time{}, false
+		}
+		o.finish(opDead, &entry)
+		return time.Time{}, false
+	}
+
+	entry.NextAt = time.Now().UTC().Add(o.backoff(entry.Attempts))
+	o.logger.Warn("outbox delivery failed", "id", entry.ID, "attempts", entry.Attempts, "retry_at", entry.NextAt, "error", err)
+	if werr := o.append(opRetry, &entry); werr != nil {
+		o.logger.Error("outbox write", "id", entry.ID, "error", werr)
+	}
+	o.pending[entry.ID] = &entry
+
+	return entry.NextAt, true
+}
+
+// backoff returns the jittered delay after the given number of failures.
+func (o *Outbox) backoff(failures int) time.Duration {
+	delay := float64(o.opts.BaseDelay) * math.Pow(2, float64(failures-1))
+	if delay > float64(o.opts.MaxDelay) {
+		delay = float64(o.opts.MaxDelay)
+	}
+	return time.Duration(delay/2 + mathrand.Float64()*delay/2)
+}
+
+// finish removes entry from the queue, truncating the file once nothing is
+// left so it does not grow without bound. Callers hold o.mu.
+func (o *Outbox) finish(op string, entry *Entry) {
+	delete(o.pending, entry.ID)
+
+	if len(o.pending) == 0 {
+		if err := o.truncate(); err == nil {
+			return
+		}
+	}
+	if err := o.append(op, entry); err != nil {
+		o.logger.Error("outbox write", "id", entry.ID, "error", err)
+	}
+}
+
+func (o *Outbox) deadLetter(entry *Entry) error {
+	f, err := os.OpenFile(filepath.Join(o.dir, DeadLetterFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := writeRecord(f, opDead, entry); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// append writes and syncs one record. Callers hold o.mu.
+func (o *Outbox) append(op string, entry *Entry) error {
+	if o.file == nil {
+		return ErrClosed
+	}
+	if err := writeRecord(o.file, op, entry); err != nil {
+		return fmt.Errorf("write outbox: %w", err)
+	}
+	if err := o.file.Sync(); err != nil {
+		return fmt.Errorf("sync outbox: %w", err)
+	}
+	return nil
+}
+
+func (o *Outbox) truncate() error {
+	if o.file == nil {
+		return ErrClosed
+	}
+	if err := o.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := o.file.Seek(0, 0); err != nil {
+		return err
+	}
+	return o.file.Sync()
+}
+
+// load replays the queue file. A torn final line from an interrupted write
+// is ignored.
+func (o *Outbox) load() error {
+	data, err := os.ReadFile(filepath.Join(o.dir, QueueFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read outbox: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64<<10), 4<<20)
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal(raw, &rec); err != nil || rec.ID == "" {
+			o.logger.Warn("outbox skipping unreadable record", "line", line)
+			continue
+		}
+
+		switch rec.Op {
+		case opQueued, opRetry:
+			entry := rec.Entry
+			o.pending[entry.ID] = &entry
+		case opDelivered, opDead:
+			delete(o.pending, rec.ID)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// compact rewrites the queue file with only the pending entries and opens it
+// for appending.
+func (o *Outbox) compact() error {
+	path := filepath.Join(o.dir, QueueFile)
+	tmp := path + ".tmp"
+
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("compact outbox: %w", err)
+	}
+	for _, entry := range o.sortedPending() {
+		if err := writeRecord(f, opQueued, &entry); err != nil {
+			f.Close()
+			return fmt.Errorf("compact outbox: %w", err)
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("compact outbox: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("compact outbox: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("compact outbox: %w", err)
+	}
+
+	o.file, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open outbox: %w", err)
+	}
+	return nil
+}
+
+// sortedPending returns copies of the pending entries, oldest first. Callers
+// hold o.mu.
+func (o *Outbox) sortedPending() []Entry {
+	out := make([]Entry, 0, len(o.pending))
+	for _, entry := range o.pending {
+		out = append(out, *entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].QueuedAt.Equal(out[j].QueuedAt) {
+			return out[i].QueuedAt.Before(out[j].QueuedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+func (o *Outbox) notify() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+func writeRecord(f *os.File, op string, entry *Entry) error {
+	data, err := json.Marshal(record{Op: op, Entry: *entry})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate outbox id: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
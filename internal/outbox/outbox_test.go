@@ -0,0 +1,151 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/contact"
+)
+
+type fakeSender struct {
+	mu       sync.Mutex
+	failures int
+	messages []contact.Message
+	calls    int
+}
+
+func (f *fakeSender) Enabled() bool { return true }
+
+func (f *fakeSender) Send(_ context.Context, msg contact.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("upstream unavailable")
+	}
+	f.messages = append(f.messages, msg)
+	return nil
+}
+
+func (f *fakeSender) delivered() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.messages)
+}
+
+func open(t *testing.T, dir string, sender contact.Sender, opts Options) *Outbox {
+	t.Helper()
+	ob, err := Open(dir, func(string) contact.Sender { return sender }, opts)
+	if err != nil {
+		t.Fatalf("open outbox: %v", err)
+	}
+	return ob
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestOutboxRetriesUntilDelivered(t *testing.T) {
+	sender := &fakeSender{failures: 2}
+	ob := open(t, t.TempDir(), sender, Options{BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ob.Run(ctx)
+
+	if err := ob.Enqueue(contact.Message{Name: "Jane", Email: "jane@example.com", Body: "Hi"}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	waitFor(t, func() bool { return sender.delivered() == 1 })
+	waitFor(t, func() bool { return ob.Pending() == 0 })
+
+	if sender.calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", sender.calls)
+	}
+}
+
+func TestOutboxDeadLetters(t *testing.T) {
+	dir := t.TempDir()
+	sender := &fakeSender{failures: 100}
+	ob := open(t, dir, sender, Options{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ob.Run(ctx)
+
+	if err := ob.Enqueue(contact.Message{Name: "Jane", Email: "jane@example.com", Body: "Hi"}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	waitFor(t, func() bool { return ob.Pending() == 0 })
+
+	data, err := os.ReadFile(filepath.Join(dir, DeadLetterFile))
+	if err != nil {
+		t.Fatalf("read dead letter: %v", err)
+	}
+	if !strings.Contains(string(data), `"attempts":3`) || !strings.Contains(string(data), "upstream unavailable") {
+		t.Fatalf("unexpected dead letter: %s", data)
+	}
+}
+
+func TestOutboxResumesAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	down := &fakeSender{failures: 100}
+
+	ob := open(t, dir, down, Options{BaseDelay: time.Hour})
+	for _, name := range []string{"Jane", "John"} {
+		if err := ob.Enqueue(contact.Message{Name: name, Email: "x@example.com", Body: "Hi"}); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+
+	// Drain tries each message once, then closes the queue.
+	if err := ob.Drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if err := ob.Enqueue(contact.Message{Name: "Late"}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed after drain, got %v", err)
+	}
+
+	// Simulate a crash mid-write with a torn trailing record.
+	f, err := os.OpenFile(filepath.Join(dir, QueueFile), os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("open queue: %v", err)
+	}
+	_, _ = f.WriteString(`{"op":"queued","id":"tor`)
+	f.Close()
+
+	up := &fakeSender{}
+	ob = open(t, dir, up, Options{BaseDelay: time.Hour})
+	if ob.Pending() != 2 {
+		t.Fatalf("expected 2 resumed messages, got %d", ob.Pending())
+	}
+
+	// Backoff from the previous run is honoured, but Drain ignores it.
+	if err := ob.Drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if up.delivered() != 2 || up.messages[0].Name != "Jane" {
+		t.Fatalf("unexpected deliveries: %+v", up.messages)
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, QueueFile)); err != nil || info.Size() != 0 {
+		t.Fatalf("expected empty queue file after delivery, got %v (%v)", info.Size(), err)
+	}
+}
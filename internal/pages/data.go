@@ -0,0 +1,78 @@
+package pages
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// DataDir is the directory, relative to the asset root, holding JSON data
+// files exposed to templates.
+const DataDir = "data"
+
+// LoadData decodes every *.json file directly inside fsys and keys the result
+// by file name without extension, so data/clients.json becomes
+// {{.Extra.clients}}. A missing directory yields an empty map.
+func LoadData(fsys fs.FS) (map[string]any, error) {
+	out := make(map[string]any)
+	if fsys == nil {
+		return out, nil
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return out, nil
+		}
+		return nil, fmt.Errorf("read data directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".json" {
+			continue
+		}
+
+		raw, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("read data file %s: %w", name, err)
+		}
+
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("decode data file %s: %w", name, err)
+		}
+
+		out[strings.TrimSuffix(name, ".json")] = value
+	}
+
+	return out, nil
+}
+
+// MergeData deep-merges layers from lowest to highest precedence. Nested
+// objects are merged key by key; any other value in a later layer replaces
+// the earlier one outright. Inputs are never modified.
+func MergeData(layers ...map[string]any) map[string]any {
+	out := make(map[string]any)
+	for _, layer := range layers {
+		mergeInto(out, layer)
+	}
+	return out
+}
+
+func mergeInto(dst, src map[string]any) {
+	for key, value := range src {
+		if child, ok := value.(map[string]any); ok {
+			existing, _ := dst[key].(map[string]any)
+			merged := make(map[string]any, len(existing)+len(child))
+			mergeInto(merged, existing)
+			mergeInto(merged, child)
+			dst[key] = merged
+			continue
+		}
+		dst[key] = value
+	}
+}
@@ -0,0 +1,62 @@
+package pages
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadData(t *testing.T) {
+	fsys := fstest.MapFS{
+		"clients.json":  {Data: []byte(`{"acme":{"name":"Acme"}}`)},
+		"features.json": {Data: []byte(`["fast","small"]`)},
+		"notes.txt":     {Data: []byte("ignored")},
+		"nested/x.json": {Data: []byte(`{}`)},
+	}
+
+	data, err := LoadData(fsys)
+	if err != nil {
+		t.Fatalf("load data: %v", err)
+	}
+
+	want := map[string]any{
+		"clients":  map[string]any{"acme": map[string]any{"name": "Acme"}},
+		"features": []any{"fast", "small"},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Fatalf("unexpected data: %#v", data)
+	}
+
+	if _, err := LoadData(fstest.MapFS{"bad.json": {Data: []byte("{")}}); err == nil {
+		t.Fatal("expected error for invalid JSON")
+	}
+}
+
+func TestMergeData(t *testing.T) {
+	files := map[string]any{
+		"brand": map[string]any{"name": "Files", "color": "blue"},
+		"list":  []any{1, 2},
+	}
+	site := map[string]any{
+		"brand":   map[string]any{"name": "Site"},
+		"tagline": "from site",
+	}
+	route := map[string]any{
+		"brand": map[string]any{"color": "red"},
+		"list":  []any{3},
+	}
+
+	got := MergeData(files, site, route)
+	want := map[string]any{
+		"brand":   map[string]any{"name": "Site", "color": "red"},
+		"list":    []any{3},
+		"tagline": "from site",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected merge: %#v", got)
+	}
+
+	if files["brand"].(map[string]any)["name"] != "Files" {
+		t.Fatal("MergeData modified its input")
+	}
+}
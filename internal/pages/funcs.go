@@ -0,0 +1,182 @@
+package pages
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/assets"
+	"github.com/elchemista/LandingGo/internal/csrf"
+	"github.com/elchemista/LandingGo/internal/markdown"
+)
+
+// FuncOptions configures the built-in template functions.
+type FuncOptions struct {
+	// BaseURL is prefixed to site-relative paths by absURL.
+	BaseURL string
+	// Manifest resolves fingerprinted and versioned asset URLs. It may be
+	// nil, in which case asset returns paths unchanged.
+	Manifest *assets.Manifest
+	// Now overrides the clock used by now; defaults to time.Now.
+	Now func() time.Time
+}
+
+// Funcs returns the standard template function set:
+//
+//	absURL      "/about"                   -> "https://example.com/about"
+//	asset       "/static/app.css"          -> "/static/app.3f9a1c2b.css"
+//	dateFormat  "2 Jan 2006" .Date         -> formatted date
+//	toJSON      (dict "name" .Title)       -> JSON safe for <script> blocks
+//	safeHTML    "<b>trusted</b>"           -> unescaped HTML
+//	markdownify "**bold**"                 -> rendered Markdown
+//	default     "fallback" .Value          -> .Value, or "fallback" when empty
+//	dict/list                              -> build maps and slices inline
+//	now                                    -> current time
+//	csrfToken                              -> CSRF token, filled in per request
+//	csrfField                              -> hidden input carrying csrfToken
+func Funcs(opts FuncOptions) template.FuncMap {
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	return template.FuncMap{
+		"absURL": func(p string) string {
+			return AbsURL(opts.BaseURL, p)
+		},
+		"asset": func(p string) string {
+			return AssetURL(opts.Manifest, p)
+		},
+		"dateFormat":  dateFormat,
+		"toJSON":      toJSON,
+		"safeHTML":    func(s string) template.HTML { return template.HTML(s) },
+		"markdownify": func(s string) template.HTML { return template.HTML(markdown.RenderInline([]byte(s))) },
+		"default":     defaultValue,
+		"dict":        dict,
+		"list":        func(items ...any) []any { return items },
+		"now":         now,
+		"csrfToken":   func() string { return csrf.Placeholder },
+		"csrfField": func() template.HTML {
+			return template.HTML(`<input type="hidden" name="` + csrf.FieldName + `" value="` + csrf.Placeholder + `">`)
+		},
+	}
+}
+
+// AbsURL resolves a site-relative path against base. Absolute and
+// protocol-relative URLs are returned unchanged.
+func AbsURL(base, p string) string {
+	if u, err := url.Parse(p); err == nil && u.IsAbs() {
+		return p
+	}
+	if strings.HasPrefix(p, "//") {
+		return p
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(p, "/")
+}
+
+// AssetURL returns the cache-busting URL for p: the fingerprinted copy when
+// the packer produced one, otherwise the original path with a ?v= content
+// hash. Unknown paths are returned unchanged.
+func AssetURL(manifest *assets.Manifest, p string) string {
+	if manifest == nil {
+		return p
+	}
+
+	rel := strings.TrimPrefix(p, "/")
+	if hashed, ok := manifest.Fingerprint(rel); ok {
+		return "/" + hashed
+	}
+	if manifest.IsFingerprinted(rel) {
+		return p
+	}
+	if entry, ok := manifest.Files[rel]; ok && len(entry.SHA256) >= 8 {
+		return p + "?v=" + entry.SHA256[:8]
+	}
+
+	return p
+}
+
+// dateFormat formats value with a Go reference layout. Values may be
+// time.Time, *time.Time, or strings in RFC 3339 or 2006-01-02 form.
+func dateFormat(layout string, value any) (string, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(layout), nil
+	case *time.Time:
+		if v == nil {
+			return "", nil
+		}
+		return v.Format(layout), nil
+	case string:
+		if v == "" {
+			return "", nil
+		}
+		for _, candidate := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(candidate, v); err == nil {
+				return t.Format(layout), nil
+			}
+		}
+		return "", fmt.Errorf("dateFormat: unrecognised date %q", v)
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("dateFormat: unsupported type %T", value)
+	}
+}
+
+// toJSON marshals v for embedding in a script element. encoding/json escapes
+// <, > and & so the output cannot terminate the surrounding element.
+func toJSON(v any) (template.JS, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return template.JS(data), nil
+}
+
+// defaultValue returns given unless it is empty, in which case def is used.
+// Arguments are ordered for pipelines: {{ .Title | default "Untitled" }}.
+func defaultValue(def any, given ...any) any {
+	if len(given) == 0 || isEmpty(given[0]) {
+		return def
+	}
+	return given[0]
+}
+
+func isEmpty(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return rv.IsZero()
+	}
+}
+
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("dict: requires an even number of arguments")
+	}
+
+	out := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", pairs[i])
+		}
+		out[key] = pairs[i+1]
+	}
+
+	return out, nil
+}
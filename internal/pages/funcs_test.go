@@ -0,0 +1,64 @@
+package pages
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/assets"
+)
+
+func TestFuncs(t *testing.T) {
+	manifest := &assets.Manifest{
+		Files: map[string]assets.ManifestEntry{
+			"static/app.css":          {Path: "static/app.css", SHA256: "3f9a1c2b00"},
+			"static/app.3f9a1c2b.css": {Path: "static/app.3f9a1c2b.css", SHA256: "3f9a1c2b00", Original: "static/app.css"},
+			"static/logo.svg":         {Path: "static/logo.svg", SHA256: "abcdef0123"},
+		},
+		Fingerprints: map[string]string{"static/app.css": "static/app.3f9a1c2b.css"},
+	}
+	fixed := time.Date(2024, 3, 9, 10, 0, 0, 0, time.UTC)
+
+	funcs := Funcs(FuncOptions{
+		BaseURL:  "https://example.com/",
+		Manifest: manifest,
+		Now:      func() time.Time { return fixed },
+	})
+
+	cases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"absURL", `{{absURL "/about"}}|{{absURL "https://cdn.example.com/x"}}`, "https://example.com/about|https://cdn.example.com/x"},
+		{"asset", `{{asset "/static/app.css"}}|{{asset "/static/logo.svg"}}|{{asset "/static/missing.js"}}`, "/static/app.3f9a1c2b.css|/static/logo.svg?v=abcdef01|/static/missing.js"},
+		{"dateFormat", `{{dateFormat "2 Jan 2006" now}}|{{dateFormat "Jan 2006" "2023-11-05"}}`, "9 Mar 2024|Nov 2023"},
+		{"toJSON", `<script type="application/ld+json">{{toJSON (dict "name" "</script>" "tags" (list "a" "b"))}}</script>`, `<script type="application/ld+json">{"name":"\u003c/script\u003e","tags":["a","b"]}</script>`},
+		{"safeHTML", `{{safeHTML "<b>ok</b>"}}`, "<b>ok</b>"},
+		{"markdownify", `{{markdownify "Hello **world**"}}`, "Hello <strong>world</strong>"},
+		{"default", `{{.Title | default "Untitled"}}|{{default "x" .RoutePath}}`, "Untitled|/here"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mgr := New(fstest.MapFS{"page.html": {Data: []byte(tc.src)}}, funcs)
+			out, err := mgr.Render("page.html", PageData{RoutePath: "/here"})
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			if got := strings.TrimSpace(string(out)); got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDictRejectsOddArguments(t *testing.T) {
+	if _, err := dict("a"); err == nil {
+		t.Fatal("expected error for odd argument count")
+	}
+	if _, err := dict(1, "b"); err == nil {
+		t.Fatal("expected error for non-string key")
+	}
+}
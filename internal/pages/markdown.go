@@ -0,0 +1,73 @@
+package pages
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/elchemista/LandingGo/internal/content"
+	"github.com/elchemista/LandingGo/internal/markdown"
+)
+
+// DefaultLayout wraps Markdown pages whose front matter names no layout.
+const DefaultLayout = "base.html"
+
+// markdownContent fills a layout's "content" block with the rendered page.
+const markdownContent = `{{define "content"}}{{.Content}}{{end}}`
+
+// IsMarkdown reports whether name is a Markdown page rather than a template.
+func IsMarkdown(name string) bool {
+	return strings.EqualFold(path.Ext(name), ".md")
+}
+
+// MarkdownPage is a parsed Markdown page.
+type MarkdownPage struct {
+	// Fields are the front matter fields. title, description, og_image and
+	// noindex override the route's values; draft hides the page outside
+	// development.
+	Fields content.Fields
+	// Layout is the layout wrapping the page, relative to the pages root.
+	Layout string
+	// HTML is the rendered body.
+	HTML []byte
+}
+
+// Draft reports whether the page is marked as a draft.
+func (p MarkdownPage) Draft() bool {
+	return p.Fields.Bool("draft")
+}
+
+// ParseMarkdown reads a Markdown page's front matter and renders its body.
+func ParseMarkdown(src []byte) (MarkdownPage, error) {
+	fields, body, err := content.ParseFrontMatter(src)
+	if err != nil {
+		return MarkdownPage{}, err
+	}
+
+	layout := fields.String("layout")
+	if _, ok := fields["layout"]; ok && layout == "" {
+		return MarkdownPage{}, errors.New("front matter: layout must be a non-empty string")
+	}
+	if layout == "" {
+		layout = DefaultLayout
+	}
+
+	return MarkdownPage{
+		Fields: fields,
+		Layout: layoutPath(layout),
+		HTML:   markdown.Render(body),
+	}, nil
+}
+
+// PageLayout returns the layout the named page uses, relative to the pages
+// root, or "" when it stands alone.
+func PageLayout(name string, src []byte) string {
+	if !IsMarkdown(name) {
+		return LayoutOf(src)
+	}
+	page, err := ParseMarkdown(src)
+	if err != nil {
+		return ""
+	}
+	return page.Layout
+}
@@ -2,16 +2,40 @@ package pages
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"html/template"
 	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
 	"sync"
 )
 
+const (
+	// LayoutsDir holds templates pages can extend.
+	LayoutsDir = "_layouts"
+	// PartialsDir holds shared snippets available to every page.
+	PartialsDir = "_partials"
+)
+
+// layoutDirective matches a leading {{/* layout: base.html */}} comment that
+// declares which layout a page extends.
+var layoutDirective = regexp.MustCompile(`^\s*\{\{-?\s*/\*\s*layout:\s*"?([^"*\s]+)"?\s*\*/\s*-?\}\}`)
+
 // Manager handles template parsing and rendering.
 type Manager struct {
 	fs        fs.FS
 	funcs     template.FuncMap
-	templates sync.Map // string -> *template.Template
+	templates sync.Map // string -> *page
+}
+
+// page is a parsed page template. Markdown pages also keep their front
+// matter and rendered body.
+type page struct {
+	tmpl     *template.Template
+	markdown *MarkdownPage
 }
 
 // New constructs a Manager for the provided filesystem containing page templates.
@@ -33,23 +57,64 @@ type PageData struct {
 	NowRFC3339 string
 	RoutePath  string
 	Extra      map[string]any
+
+	// SEO metadata from the route configuration. Canonical, OGImage and the
+	// alternate URLs are absolute.
+	Description string
+	Canonical   string
+	OGImage     string
+	NoIndex     bool
+	Lang        string
+	Alternates  []Alternate
+
+	// Nonce is the Content-Security-Policy nonce for inline scripts, as in
+	// <script nonce="{{.Nonce}}">. It is empty when CSP is disabled.
+	Nonce string
+
+	// Entry holds the fields of the collection entry a parameterised route
+	// is rendering, plus its "slug"; Content is the entry's rendered body.
+	Entry   map[string]any
+	Content template.HTML
+}
+
+// Alternate links a translated version of a page.
+type Alternate struct {
+	Hreflang string
+	URL      string
 }
 
-// Render executes the named template with the provided data.
+// Render executes the named template with the provided data. A Markdown
+// page is rendered into its layout's "content" block and as data.Content.
 func (m *Manager) Render(name string, data PageData) ([]byte, error) {
-	tmpl, err := m.template(name)
+	p, err := m.page(name)
 	if err != nil {
 		return nil, err
 	}
+	if p.markdown != nil {
+		data.Content = template.HTML(p.markdown.HTML)
+	}
 
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
+	if err := p.tmpl.Execute(&buf, data); err != nil {
 		return nil, err
 	}
 
 	return buf.Bytes(), nil
 }
 
+// Markdown returns the named Markdown page as parsed alongside its template.
+// It reports false for template pages.
+func (m *Manager) Markdown(name string) (MarkdownPage, bool, error) {
+	if !IsMarkdown(name) {
+		return MarkdownPage{}, false, nil
+	}
+	p, err := m.page(name)
+	if err != nil {
+		return MarkdownPage{}, false, err
+	}
+	return *p.markdown, true, nil
+}
+
 // Exists reports whether the template file exists.
 func (m *Manager) Exists(name string) bool {
 	if m == nil || name == "" {
@@ -60,20 +125,56 @@ func (m *Manager) Exists(name string) bool {
 }
 
 // Invalidate releases a template from the cache (useful in dev hot-reload).
+// Layouts and partials are shared by every page, so changing one of them
+// drops the whole cache.
 func (m *Manager) Invalidate(name string) {
 	if m == nil || name == "" {
 		return
 	}
+
+	if IsShared(name) {
+		m.templates.Range(func(key, _ any) bool {
+			m.templates.Delete(key)
+			return true
+		})
+		return
+	}
+
 	m.templates.Delete(name)
 }
 
-func (m *Manager) template(name string) (*template.Template, error) {
+// IsShared reports whether name lives in the layouts or partials directory.
+func IsShared(name string) bool {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+	return strings.HasPrefix(name, LayoutsDir+"/") || strings.HasPrefix(name, PartialsDir+"/")
+}
+
+// LayoutOf returns the layout a page declares, as a path relative to the
+// pages root, or "" when the page stands alone.
+func LayoutOf(src []byte) string {
+	match := layoutDirective.FindSubmatch(src)
+	if match == nil {
+		return ""
+	}
+
+	return layoutPath(string(match[1]))
+}
+
+// layoutPath resolves a layout name such as "base" to its template path.
+func layoutPath(layout string) string {
+	if path.Ext(layout) == "" {
+		layout += ".html"
+	}
+	return path.Join(LayoutsDir, layout)
+}
+
+func (m *Manager) page(name string) (*page, error) {
 	if m == nil {
 		return nil, fs.ErrNotExist
 	}
 
 	if v, ok := m.templates.Load(name); ok {
-		return v.(*template.Template), nil
+		return v.(*page), nil
 	}
 
 	src, err := fs.ReadFile(m.fs, name)
@@ -81,14 +182,80 @@ func (m *Manager) template(name string) (*template.Template, error) {
 		return nil, err
 	}
 
-	tmpl, err := template.New(name).
+	p := &page{}
+	layout := LayoutOf(src)
+	if IsMarkdown(name) {
+		md, err := ParseMarkdown(src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		p.markdown = &md
+		layout = md.Layout
+		src = []byte(markdownContent)
+	}
+
+	root := template.New(name).
 		Funcs(m.funcs).
-		Option("missingkey=zero").
-		Parse(string(src))
+		Option("missingkey=zero")
+
+	partials, err := m.sharedFiles(PartialsDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, partial := range partials {
+		if err := m.parseFile(root, partial); err != nil {
+			return nil, err
+		}
+	}
+
+	// The layout is parsed before the page so the page's {{define}} blocks
+	// override the layout's {{block}} defaults.
+	if layout != "" {
+		if err := m.parseFile(root, layout); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpl, err := root.Parse(string(src))
 	if err != nil {
 		return nil, err
 	}
 
-	m.templates.Store(name, tmpl)
-	return tmpl, nil
+	if layout != "" {
+		tmpl = tmpl.Lookup(layout)
+	}
+
+	p.tmpl = tmpl
+	m.templates.Store(name, p)
+	return p, nil
+}
+
+func (m *Manager) parseFile(root *template.Template, name string) error {
+	src, err := fs.ReadFile(m.fs, name)
+	if err != nil {
+		return err
+	}
+	_, err = root.New(name).Parse(string(src))
+	return err
+}
+
+// sharedFiles lists the templates beneath dir in a stable order.
+func (m *Manager) sharedFiles(dir string) ([]string, error) {
+	var files []string
+
+	err := fs.WalkDir(m.fs, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
 }
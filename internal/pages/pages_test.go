@@ -0,0 +1,110 @@
+package pages
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRenderWithLayoutAndPartials(t *testing.T) {
+	fsys := fstest.MapFS{
+		"_layouts/base.html":  {Data: []byte(`<html><head>{{template "head" .}}<title>{{block "title" .}}{{.Title}}{{end}}</title></head><body>{{template "_partials/nav.html" .}}{{block "content" .}}default{{end}}</body></html>`)},
+		"_partials/head.html": {Data: []byte(`{{define "head"}}<link rel="stylesheet" href="/static/app.css">{{end}}`)},
+		"_partials/nav.html":  {Data: []byte(`<nav>{{.RoutePath}}</nav>`)},
+		"about.html":          {Data: []byte("{{/* layout: base */}}\n{{define \"content\"}}<h1>About us</h1>{{end}}")},
+		"plain.html":          {Data: []byte(`<html>{{template "head" .}}<p>{{.Title}}</p></html>`)},
+	}
+
+	mgr := New(fsys, nil)
+
+	out, err := mgr.Render("about.html", PageData{Title: "About", RoutePath: "/about"})
+	if err != nil {
+		t.Fatalf("render about: %v", err)
+	}
+
+	want := `<html><head><link rel="stylesheet" href="/static/app.css"><title>About</title></head><body><nav>/about</nav><h1>About us</h1></body></html>`
+	if string(out) != want {
+		t.Fatalf("unexpected layout output:\n got %s\nwant %s", out, want)
+	}
+
+	out, err = mgr.Render("plain.html", PageData{Title: "Plain"})
+	if err != nil {
+		t.Fatalf("render plain: %v", err)
+	}
+	if !strings.Contains(string(out), `app.css`) || !strings.Contains(string(out), "<p>Plain</p>") {
+		t.Fatalf("partial not available to standalone page: %s", out)
+	}
+}
+
+func TestInvalidateSharedTemplate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"_partials/head.html": {Data: []byte(`{{define "head"}}v1{{end}}`)},
+		"home.html":           {Data: []byte(`{{template "head" .}}`)},
+	}
+
+	mgr := New(fsys, nil)
+
+	if out, _ := mgr.Render("home.html", PageData{}); string(out) != "v1" {
+		t.Fatalf("unexpected first render: %s", out)
+	}
+
+	fsys["_partials/head.html"] = &fstest.MapFile{Data: []byte(`{{define "head"}}v2{{end}}`)}
+	mgr.Invalidate("_partials/head.html")
+
+	if out, _ := mgr.Render("home.html", PageData{}); string(out) != "v2" {
+		t.Fatalf("expected partial change to invalidate page, got %s", out)
+	}
+}
+
+func TestLayoutOf(t *testing.T) {
+	cases := map[string]string{
+		"{{/* layout: base */}}":                   "_layouts/base.html",
+		"  {{- /* layout: \"landing.html\" */ -}}": "_layouts/landing.html",
+		"<html>{{/* layout: base */}}":             "",
+		"{{/* just a comment */}}":                 "",
+	}
+
+	for src, want := range cases {
+		if got := LayoutOf([]byte(src)); got != want {
+			t.Fatalf("LayoutOf(%q) = %q, want %q", src, got, want)
+		}
+	}
+}
+
+func TestRenderMarkdownPage(t *testing.T) {
+	fsys := fstest.MapFS{
+		"_layouts/base.html": {Data: []byte(`<title>{{.Title}}</title><main>{{block "content" .}}{{end}}</main>`)},
+		"_layouts/docs.html": {Data: []byte(`<article>{{.Content}}</article>`)},
+		"pricing.md":         {Data: []byte("---\ntitle: Pricing\ndraft: true\n---\n# Plans {{.Title}}\n\n![Chart](/static/chart.png)\n")},
+		"faq.md":             {Data: []byte("{\"layout\": \"docs\"}\n**Questions**\n")},
+		"broken.md":          {Data: []byte("---\nlayout:\n---\n")},
+	}
+
+	mgr := New(fsys, nil)
+
+	out, err := mgr.Render("pricing.md", PageData{Title: "Plans"})
+	if err != nil {
+		t.Fatalf("render pricing: %v", err)
+	}
+	want := "<title>Plans</title><main><h1>Plans {{.Title}}</h1>\n<p><img src=\"/static/chart.png\" alt=\"Chart\"></p>\n</main>"
+	if string(out) != want {
+		t.Fatalf("unexpected Markdown output:\n got %s\nwant %s", out, want)
+	}
+
+	page, ok, err := mgr.Markdown("pricing.md")
+	if err != nil || !ok || !page.Draft() || page.Fields.String("title") != "Pricing" || page.Layout != "_layouts/base.html" {
+		t.Fatalf("unexpected Markdown page: %+v, %v, %v", page, ok, err)
+	}
+
+	if out, err := mgr.Render("faq.md", PageData{}); err != nil || string(out) != "<article><p><strong>Questions</strong></p>\n</article>" {
+		t.Fatalf("unexpected JSON front matter page: %s, %v", out, err)
+	}
+
+	if _, err := mgr.Render("broken.md", PageData{}); err == nil || !strings.Contains(err.Error(), "broken.md") {
+		t.Fatalf("expected front matter error naming the page, got %v", err)
+	}
+
+	if _, ok, err := mgr.Markdown("_layouts/base.html"); ok || err != nil {
+		t.Fatalf("template pages are not Markdown: %v, %v", ok, err)
+	}
+}
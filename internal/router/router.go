@@ -1,10 +1,15 @@
 package router
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+	"strings"
+)
 
 // Router wires HTTP handlers without relying on ServeMux so custom 404 logic is possible.
 type Router struct {
 	exact    map[string]http.Handler
+	patterns []patternHandler
 	prefixes []prefixHandler
 	notFound http.Handler
 }
@@ -14,6 +19,14 @@ type prefixHandler struct {
 	handler http.Handler
 }
 
+type patternHandler struct {
+	segments []string
+	handler  http.Handler
+}
+
+// keyParams is used to stash pattern parameters in the context.
+type keyParams struct{}
+
 // New constructs a fresh Router.
 func New() *Router {
 	return &Router{
@@ -37,6 +50,24 @@ func (r *Router) HandleFunc(path string, fn http.HandlerFunc) {
 	r.Handle(path, http.HandlerFunc(fn))
 }
 
+// HandlePattern registers a path whose "{name}" segments each match one
+// non-empty path segment, such as /work/{slug}. Exact matches take precedence
+// over patterns, and patterns over prefixes; patterns are tried in
+// registration order. Handlers read the values with Param.
+func (r *Router) HandlePattern(pattern string, handler http.Handler) {
+	if pattern == "" || handler == nil {
+		return
+	}
+	r.patterns = append(r.patterns, patternHandler{segments: strings.Split(pattern, "/"), handler: handler})
+}
+
+// Param returns the value of the named pattern parameter, or "" when the
+// request was not routed through a pattern that declares it.
+func Param(req *http.Request, name string) string {
+	params, _ := req.Context().Value(keyParams{}).(map[string]string)
+	return params[name]
+}
+
 // HandlePrefix registers a prefix match (e.g. for static assets).
 func (r *Router) HandlePrefix(prefix string, handler http.Handler) {
 	if prefix == "" || handler == nil {
@@ -57,6 +88,17 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if len(r.patterns) > 0 {
+		segments := strings.Split(req.URL.Path, "/")
+		for _, ph := range r.patterns {
+			if params, ok := ph.match(segments); ok {
+				ctx := context.WithValue(req.Context(), keyParams{}, params)
+				ph.handler.ServeHTTP(w, req.WithContext(ctx))
+				return
+			}
+		}
+	}
+
 	for _, ph := range r.prefixes {
 		if ph.handler == nil {
 			continue
@@ -74,3 +116,29 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 	http.NotFound(w, req)
 }
+
+func (ph patternHandler) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(ph.segments) {
+		return nil, false
+	}
+
+	var params map[string]string
+	for i, want := range ph.segments {
+		name, isParam := strings.CutPrefix(want, "{")
+		if name, ok := strings.CutSuffix(name, "}"); isParam && ok {
+			if segments[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = segments[i]
+			continue
+		}
+		if segments[i] != want {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
@@ -0,0 +1,35 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterPrecedence(t *testing.T) {
+	r := New()
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte(name + ":" + Param(req, "slug")))
+		})
+	}
+	r.Handle("/work/featured", handler("exact"))
+	r.HandlePattern("/work/{slug}", handler("pattern"))
+	r.HandlePrefix("/work/", handler("prefix"))
+	r.NotFound(handler("missing"))
+
+	cases := map[string]string{
+		"/work/featured": "exact:",
+		"/work/acme":     "pattern:acme",
+		"/work/acme/x":   "prefix:",
+		"/work/":         "prefix:",
+		"/about":         "missing:",
+	}
+	for path, want := range cases {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if got := rec.Body.String(); got != want {
+			t.Fatalf("%s: got %q, want %q", path, got, want)
+		}
+	}
+}
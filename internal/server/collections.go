@@ -0,0 +1,139 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+
+	"github.com/elchemista/LandingGo/internal/assets"
+	"github.com/elchemista/LandingGo/internal/config"
+	"github.com/elchemista/LandingGo/internal/content"
+	"github.com/elchemista/LandingGo/internal/router"
+)
+
+// collectionEntry is a collection entry with the concrete route serving it.
+type collectionEntry struct {
+	route config.Route
+	entry content.Entry
+}
+
+// loadEntries reads the entries of every collection route, keyed by the
+// path each one is served at. Draft entries are only loaded in development.
+func loadEntries(src *assets.Source, routes []config.Route, dev bool) (map[string]collectionEntry, error) {
+	exact := make(map[string]struct{}, len(routes))
+	for _, route := range routes {
+		if !route.IsCollection() {
+			exact[route.Path] = struct{}{}
+		}
+	}
+
+	entries := make(map[string]collectionEntry)
+	for _, route := range routes {
+		if !route.IsCollection() {
+			continue
+		}
+
+		list, err := content.Load(src.FS, route.Collection)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %w", route.Path, err)
+		}
+
+		for _, entry := range list {
+			if entry.Bool("draft") && !dev {
+				continue
+			}
+			path := route.EntryPath(entry.Slug)
+			if _, ok := exact[path]; ok {
+				return nil, fmt.Errorf("route %s: entry %s conflicts with route %s", route.Path, entry.File, path)
+			}
+			if other, ok := entries[path]; ok {
+				return nil, fmt.Errorf("route %s: entry %s conflicts with %s", route.Path, entry.File, other.entry.File)
+			}
+			entryRoute := route
+			entryRoute.Path = path
+			entries[path] = collectionEntry{route: withFields(entryRoute, entry.Fields), entry: entry}
+		}
+	}
+
+	return entries, nil
+}
+
+// withFields returns route with the title, description, og_image and
+// noindex fields of an entry or a page's front matter taking precedence.
+func withFields(route config.Route, fields content.Fields) config.Route {
+	if title := fields.String("title"); title != "" {
+		route.Title = title
+	}
+	if description := fields.String("description"); description != "" {
+		route.Description = description
+	}
+	if image := fields.String("og_image"); image != "" {
+		route.OGImage = image
+	}
+	if fields.Bool("noindex") {
+		route.NoIndex = true
+	}
+	return route
+}
+
+// withEntries replaces the collection routes with one route per entry, for
+// the sitemap. Drafts loaded in development are left out.
+func withEntries(routes []config.Route, entries map[string]collectionEntry) []config.Route {
+	out := make([]config.Route, 0, len(routes)+len(entries))
+	for _, route := range routes {
+		if !route.IsCollection() {
+			out = append(out, route)
+		}
+	}
+	for _, ce := range entries {
+		if !ce.entry.Bool("draft") {
+			out = append(out, ce.route)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// entry returns the collection entry served at path.
+func (s *Server) entry(path string) (collectionEntry, bool) {
+	s.dataMu.RLock()
+	defer s.dataMu.RUnlock()
+	ce, ok := s.entries[path]
+	return ce, ok
+}
+
+// reloadEntries re-reads collection entries after a change in dev mode. A
+// broken entry keeps the previous set so pages stay viewable while editing.
+func (s *Server) reloadEntries() {
+	entries, err := loadEntries(s.source, s.cfg.Routes, s.dev)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Error("reload content", "error", err)
+		}
+		return
+	}
+
+	s.dataMu.Lock()
+	s.entries = entries
+	s.dataMu.Unlock()
+}
+
+func (s *Server) serveEntry(w http.ResponseWriter, r *http.Request, route config.Route) {
+	ce, ok := s.entry(route.EntryPath(router.Param(r, "slug")))
+	if !ok {
+		s.serveNotFound(w, r)
+		return
+	}
+	s.servePage(w, r, ce.route)
+}
+
+// entryData exposes an entry's fields, with its slug, to templates.
+func entryData(entry content.Entry) (map[string]any, template.HTML) {
+	fields := make(map[string]any, len(entry.Fields)+1)
+	for key, val := range entry.Fields {
+		fields[key] = val
+	}
+	fields["slug"] = entry.Slug
+	return fields, template.HTML(entry.HTML)
+}
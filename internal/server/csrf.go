@@ -0,0 +1,75 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/csrf"
+	"github.com/elchemista/LandingGo/internal/middleware"
+)
+
+var csrfPlaceholder = []byte(csrf.Placeholder)
+
+// rejectForgery checks that a form submission comes from this site or an
+// allowlisted partner and, when tokens are required, that it carries a
+// valid token. Failures are logged and answered with the 403 page.
+func (s *Server) rejectForgery(w http.ResponseWriter, r *http.Request, f *form) bool {
+	partner, err := s.csrf.CheckOrigin(r)
+	if err == nil && !partner && s.csrf.RequireTokens() {
+		err = s.verifyCSRFToken(r)
+	}
+	if err == nil {
+		return false
+	}
+
+	if s.logger != nil {
+		s.logger.Warn("csrf check failed",
+			"form", f.cfg.Name,
+			"error", err,
+			"origin", r.Header.Get("Origin"),
+			"sec_fetch_site", r.Header.Get("Sec-Fetch-Site"),
+			"ip", middleware.ClientIP(r),
+			"request_id", middleware.RequestIDFromContext(r.Context()),
+		)
+	}
+
+	s.serveError(w, r, http.StatusForbidden)
+	return true
+}
+
+func (s *Server) verifyCSRFToken(r *http.Request) error {
+	cookie, err := r.Cookie(csrf.CookieName)
+	if err != nil {
+		return csrf.ErrToken
+	}
+	token := r.PostForm.Get(csrf.FieldName)
+	if token == "" {
+		token = r.Header.Get(csrf.HeaderName)
+	}
+	return s.csrf.Verify(token, cookie.Value, time.Now())
+}
+
+// fillCSRF replaces the csrfToken placeholders in a rendered page with a
+// token bound to the visitor's nonce cookie, setting the cookie first if
+// needed. Pages with tokens are personal, so they are never cached.
+func (s *Server) fillCSRF(w http.ResponseWriter, r *http.Request, body []byte) []byte {
+	nonce := ""
+	if cookie, err := r.Cookie(csrf.CookieName); err == nil && cookie.Value != "" {
+		nonce = cookie.Value
+	} else {
+		nonce = csrf.NewNonce()
+		http.SetCookie(w, &http.Cookie{
+			Name:     csrf.CookieName,
+			Value:    nonce,
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   r.TLS != nil || strings.HasPrefix(s.cfg.Site.BaseURL, "https://"),
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	markPersonal(w)
+	return bytes.ReplaceAll(body, csrfPlaceholder, []byte(s.csrf.Token(nonce, time.Now())))
+}
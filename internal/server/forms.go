@@ -0,0 +1,185 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/captcha"
+	"github.com/elchemista/LandingGo/internal/config"
+	"github.com/elchemista/LandingGo/internal/contact"
+	"github.com/elchemista/LandingGo/internal/middleware"
+	"github.com/elchemista/LandingGo/internal/spam"
+)
+
+// formTokenPath serves time-trap tokens for forms with spam.min_seconds.
+const formTokenPath = "/form-token"
+
+// form is a configured form bound to its delivery backend.
+type form struct {
+	cfg     config.Form
+	sender  contact.Sender
+	spam    *spam.Filter
+	captcha *captcha.Verifier
+}
+
+// buildForms returns the configured forms keyed by endpoint path. /contact is
+// always present so the default contact page keeps a stable endpoint; without
+// configuration its sender is nil and submissions are refused. Configured
+// forms that still lack credentials are logged, since they answer 503.
+func buildForms(cfg *config.Config, signer *spam.Signer, limiters *spam.Limiters, logger *slog.Logger) map[string]*form {
+	set := cfg.FormSet()
+	forms := make(map[string]*form, len(set)+1)
+
+	for _, fc := range set {
+		f := &form{cfg: fc, spam: spam.New(fc.Spam, signer, limiters, fc.Name), captcha: captcha.New(fc.Captcha, nil)}
+		if fc.Enabled() {
+			f.sender = contact.New(fc.Contact)
+		} else if logger != nil {
+			logger.Warn("form delivery disabled: backend credentials missing",
+				"form", fc.Name,
+				"backend", fc.BackendName(),
+			)
+		}
+		forms[fc.Path] = f
+	}
+
+	if _, ok := forms["/contact"]; !ok {
+		forms["/contact"] = &form{cfg: config.Form{Name: config.ContactForm, Path: "/contact"}}
+	}
+
+	return forms
+}
+
+// Sender returns the sender of the named form built from this generation's
+// configuration, or nil when the form is missing or not configured. An empty
+// name selects the contact form.
+func (s *Server) Sender(name string) contact.Sender {
+	if s == nil {
+		return nil
+	}
+	if name == "" {
+		name = config.ContactForm
+	}
+	for _, f := range s.forms {
+		if f.cfg.Name == name {
+			return f.sender
+		}
+	}
+	return nil
+}
+
+// route fills in the form name, subject and recipients of msg from the
+// form's subject template and routing rules.
+func (f *form) route(msg contact.Message) contact.Message {
+	values := msg.Values()
+	msg.Form = f.cfg.Name
+	msg.Subject = f.cfg.RenderSubject(values)
+	msg.To, msg.CC = f.cfg.Recipients(values)
+	return msg
+}
+
+// usesFormTokens reports whether any form needs time-trap tokens.
+func (s *Server) usesFormTokens() bool {
+	for _, f := range s.forms {
+		if f.spam.UsesToken() {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) serveFormToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		s.writeStatus(w, http.StatusMethodNotAllowed)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]string{"token": s.tokens.Issue(time.Now())})
+}
+
+// rejectSpam runs the form's spam checks. A rejected submission is logged
+// and answered exactly like an accepted one so bots get no signal.
+func (s *Server) rejectSpam(w http.ResponseWriter, r *http.Request, f *form) bool {
+	now := time.Now()
+	ip := middleware.ClientIP(r)
+
+	reason := spam.ReasonRateLimited
+	if f.spam.Allow(ip, now) {
+		reason = f.spam.Check(r.PostForm, now)
+	}
+	if reason == "" {
+		return false
+	}
+
+	s.fakeAccept(w, r, f, reason)
+	return true
+}
+
+// fakeAccept logs a spam rejection and answers exactly like an accepted
+// submission so bots get no signal.
+func (s *Server) fakeAccept(w http.ResponseWriter, r *http.Request, f *form, reason string) {
+	ip := middleware.ClientIP(r)
+	if s.logger != nil {
+		s.logger.Warn("contact spam rejected",
+			"form", f.cfg.Name,
+			"reason", reason,
+			"ip", ip,
+			"request_id", middleware.RequestIDFromContext(r.Context()),
+		)
+	}
+
+	status := "sent"
+	if s.outbox != nil {
+		status = "queued"
+	}
+	s.writeJSON(w, http.StatusAccepted, map[string]string{"status": status})
+}
+
+// captchaCodes maps verification errors to the code reported to the page.
+var captchaCodes = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{captcha.ErrMissing, http.StatusBadRequest, "captcha_required"},
+	{captcha.ErrRejected, http.StatusBadRequest, "captcha_invalid"},
+	{captcha.ErrLowScore, http.StatusBadRequest, "captcha_low_score"},
+	{captcha.ErrUnavailable, http.StatusServiceUnavailable, "captcha_unavailable"},
+}
+
+// rejectCaptcha verifies the form's CAPTCHA token, if one is configured, and
+// answers failures with a JSON error carrying a "code" the page can act on.
+func (s *Server) rejectCaptcha(w http.ResponseWriter, r *http.Request, f *form) bool {
+	if f.captcha == nil {
+		return false
+	}
+
+	ip := middleware.ClientIP(r)
+	err := f.captcha.Verify(r.Context(), r.PostForm.Get(f.captcha.Field()), ip)
+	if err == nil {
+		return false
+	}
+
+	status, code := http.StatusBadRequest, "captcha_invalid"
+	for _, c := range captchaCodes {
+		if errors.Is(err, c.err) {
+			status, code = c.status, c.code
+			break
+		}
+	}
+
+	if s.logger != nil {
+		s.logger.Warn("contact captcha failed",
+			"form", f.cfg.Name,
+			"code", code,
+			"ip", ip,
+			"request_id", middleware.RequestIDFromContext(r.Context()),
+			"error", err,
+		)
+	}
+
+	s.writeJSON(w, status, map[string]string{"error": "captcha verification failed", "code": code})
+	return true
+}
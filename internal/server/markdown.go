@@ -0,0 +1,50 @@
+package server
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/elchemista/LandingGo/internal/assets"
+	"github.com/elchemista/LandingGo/internal/config"
+	"github.com/elchemista/LandingGo/internal/pages"
+)
+
+// markdownRoute applies a Markdown page's front matter to its route. It
+// reports false for drafts outside development. A page that fails to parse
+// keeps the route unchanged so rendering reports the error.
+func (s *Server) markdownRoute(route config.Route) (config.Route, bool) {
+	page, ok, err := s.pageMgr.Markdown(route.Page)
+	if !ok || err != nil {
+		return route, true
+	}
+	if page.Draft() && !s.dev {
+		return route, false
+	}
+	return withFields(route, page.Fields), true
+}
+
+// markdownRoutes applies front matter to the Markdown routes and drops
+// drafts, for the sitemap. A page that cannot be read or parsed is an error
+// rather than a silent omission.
+func markdownRoutes(src *assets.Source, routes []config.Route) ([]config.Route, error) {
+	out := make([]config.Route, 0, len(routes))
+	for _, route := range routes {
+		if pages.IsMarkdown(route.Page) {
+			data, err := fs.ReadFile(src.FS, path.Join("pages", route.Page))
+			if err != nil {
+				return nil, fmt.Errorf("route %s: %w", route.Path, err)
+			}
+			page, err := pages.ParseMarkdown(data)
+			if err != nil {
+				return nil, fmt.Errorf("route %s: page %s: %w", route.Path, route.Page, err)
+			}
+			if page.Draft() {
+				continue
+			}
+			route = withFields(route, page.Fields)
+		}
+		out = append(out, route)
+	}
+	return out, nil
+}
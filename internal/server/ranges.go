@@ -0,0 +1,128 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRanges bounds how many ranges a single request may ask for; larger sets
+// are answered with the full representation.
+const maxRanges = 16
+
+var errUnsatisfiableRange = errors.New("range not satisfiable")
+
+// byteRange is a resolved, inclusive-exclusive slice of a representation.
+type byteRange struct {
+	start  int64
+	length int64
+}
+
+func (br byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", br.start, br.start+br.length-1, size)
+}
+
+// parseRange parses a Range header value against a representation of size
+// bytes (RFC 9110 section 14.1.2). A nil slice with a nil error means the
+// header should be ignored and the full representation served.
+func parseRange(header string, size int64) ([]byteRange, error) {
+	unit, spec, ok := strings.Cut(header, "=")
+	if !ok || !strings.EqualFold(strings.TrimSpace(unit), "bytes") {
+		return nil, nil
+	}
+
+	parts := strings.Split(spec, ",")
+	if len(parts) > maxRanges {
+		return nil, nil
+	}
+
+	var ranges []byteRange
+	var total int64
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		first, last, ok := strings.Cut(part, "-")
+		if !ok {
+			return nil, nil
+		}
+		first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+
+		var br byteRange
+		if first == "" {
+			// suffix-byte-range-spec: the final N bytes.
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, nil
+			}
+			if n == 0 || size == 0 {
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			br = byteRange{start: size - n, length: n}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, nil
+			}
+			end := size - 1
+			if last != "" {
+				end, err = strconv.ParseInt(last, 10, 64)
+				if err != nil || end < start {
+					return nil, nil
+				}
+			}
+			if start >= size {
+				continue
+			}
+			if end >= size {
+				end = size - 1
+			}
+			br = byteRange{start: start, length: end - start + 1}
+		}
+
+		total += br.length
+		ranges = append(ranges, br)
+	}
+
+	if len(ranges) == 0 {
+		return nil, errUnsatisfiableRange
+	}
+
+	// Asking for more bytes than the whole file is pointless; serve it all.
+	if total > size {
+		return nil, nil
+	}
+
+	return ranges, nil
+}
+
+// ifRangeMatches evaluates an If-Range precondition. Only strong validators
+// qualify: an exactly matching strong ETag or an exact Last-Modified date.
+func ifRangeMatches(r *http.Request, etag string, lastModified time.Time) bool {
+	cond := strings.TrimSpace(r.Header.Get("If-Range"))
+	if cond == "" {
+		return true
+	}
+
+	if strings.HasPrefix(cond, "\"") {
+		return etag != "" && cond == etag
+	}
+	if strings.HasPrefix(cond, "W/") {
+		return false
+	}
+
+	ts, err := http.ParseTime(cond)
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+	return lastModified.UTC().Truncate(time.Second).Equal(ts.UTC())
+}
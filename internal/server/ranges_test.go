@@ -0,0 +1,125 @@
+package server
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		header string
+		want   []byteRange
+		err    error
+	}{
+		{header: "bytes=0-4", want: []byteRange{{start: 0, length: 5}}},
+		{header: "bytes=5-", want: []byteRange{{start: 5, length: 5}}},
+		{header: "bytes=-3", want: []byteRange{{start: 7, length: 3}}},
+		{header: "bytes=0-99", want: []byteRange{{start: 0, length: 10}}},
+		{header: "bytes=0-1, 4-5", want: []byteRange{{start: 0, length: 2}, {start: 4, length: 2}}},
+		{header: "bytes=20-30", err: errUnsatisfiableRange},
+		{header: "bytes=-0", err: errUnsatisfiableRange},
+		{header: "items=0-1"},
+		{header: "bytes=5-2"},
+		{header: "bytes=abc"},
+		{header: "bytes=0-9,0-9"},
+	}
+
+	for _, tc := range cases {
+		got, err := parseRange(tc.header, 10)
+		if err != tc.err {
+			t.Fatalf("%q: expected error %v, got %v", tc.header, tc.err, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%q: expected %+v, got %+v", tc.header, tc.want, got)
+		}
+	}
+}
+
+func TestStaticRangeRequests(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+	mustWrite(t, filepath.Join(src.Root(), "static", "video.mp4"), "0123456789")
+
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	do := func(headers map[string]string) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/static/video.mp4", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	full, _ := do(nil)
+	if full.Header.Get("Accept-Ranges") != "bytes" {
+		t.Fatalf("expected Accept-Ranges: bytes")
+	}
+	etag := full.Header.Get("ETag")
+
+	resp, body := do(map[string]string{"Range": "bytes=2-5"})
+	if resp.StatusCode != http.StatusPartialContent || body != "2345" {
+		t.Fatalf("single range: got %d %q", resp.StatusCode, body)
+	}
+	if cr := resp.Header.Get("Content-Range"); cr != "bytes 2-5/10" {
+		t.Fatalf("unexpected Content-Range: %s", cr)
+	}
+
+	resp, body = do(map[string]string{"Range": "bytes=0-1,8-"})
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Fatalf("multi range: expected 206, got %d", resp.StatusCode)
+	}
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/byteranges" {
+		t.Fatalf("unexpected multi-range content type: %s", resp.Header.Get("Content-Type"))
+	}
+	reader := multipart.NewReader(strings.NewReader(body), params["boundary"])
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		data, _ := io.ReadAll(part)
+		parts = append(parts, part.Header.Get("Content-Range")+"="+string(data))
+	}
+	if want := []string{"bytes 0-1/10=01", "bytes 8-9/10=89"}; !reflect.DeepEqual(parts, want) {
+		t.Fatalf("unexpected parts: %v", parts)
+	}
+
+	resp, _ = do(map[string]string{"Range": "bytes=50-60"})
+	if resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("expected 416, got %d", resp.StatusCode)
+	}
+	if cr := resp.Header.Get("Content-Range"); cr != "bytes */10" {
+		t.Fatalf("unexpected 416 Content-Range: %s", cr)
+	}
+
+	resp, body = do(map[string]string{"Range": "bytes=0-1", "If-Range": etag})
+	if resp.StatusCode != http.StatusPartialContent || body != "01" {
+		t.Fatalf("matching If-Range: got %d %q", resp.StatusCode, body)
+	}
+
+	resp, body = do(map[string]string{"Range": "bytes=0-1", "If-Range": `"stale"`})
+	if resp.StatusCode != http.StatusOK || body != "0123456789" {
+		t.Fatalf("stale If-Range should return full body: got %d %q", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// serveRedirect answers r with the configured redirect for its path. Routes,
+// built-in endpoints and static files are matched first, so redirects only
+// apply to paths that would otherwise be a 404.
+func (s *Server) serveRedirect(w http.ResponseWriter, r *http.Request) bool {
+	rd, target, ok := s.cfg.MatchRedirect(r.URL.Path)
+	if !ok {
+		return false
+	}
+
+	if r.URL.RawQuery != "" {
+		base, fragment, hasFragment := strings.Cut(target, "#")
+		sep := "?"
+		if strings.Contains(base, "?") {
+			sep = "&"
+		}
+		target = base + sep + r.URL.RawQuery
+		if hasFragment {
+			target += "#" + fragment
+		}
+	}
+
+	http.Redirect(w, r, target, rd.Status)
+	return true
+}
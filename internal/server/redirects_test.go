@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+func TestRedirects(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+	cfg.Redirects = []config.Redirect{
+		{From: "/contact", To: "/"},
+		{From: "/blog/*", To: "/news/:splat?ref=blog#top", Status: http.StatusFound},
+		{From: "/static/old.css", To: "/static/app.css", Status: http.StatusPermanentRedirect},
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	cases := []struct {
+		method   string
+		target   string
+		status   int
+		location string
+	}{
+		{http.MethodGet, "/contact?utm_source=mail", http.StatusMovedPermanently, "/?utm_source=mail"},
+		{http.MethodGet, "/blog/2024/hello?page=2", http.StatusFound, "/news/2024/hello?ref=blog&page=2#top"},
+		{http.MethodHead, "/static/old.css", http.StatusPermanentRedirect, "/static/app.css"},
+		{http.MethodGet, "/", http.StatusOK, ""},
+		{http.MethodGet, "/missing", http.StatusNotFound, ""},
+	}
+
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(tc.method, tc.target, nil))
+
+		if rec.Code != tc.status {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.target, tc.status, rec.Code)
+		}
+		if got := rec.Header().Get("Location"); got != tc.location {
+			t.Fatalf("%s %s: expected Location %q, got %q", tc.method, tc.target, tc.location, got)
+		}
+		if got := rec.Header().Get("Allow"); got != "" {
+			t.Fatalf("%s %s: unexpected Allow header %q", tc.method, tc.target, got)
+		}
+	}
+
+	// The contact endpoint keeps accepting submissions at the redirected path.
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/contact", nil))
+	if rec.Code == http.StatusMovedPermanently {
+		t.Fatalf("POST /contact should not be redirected")
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Handle routes requests to the current server generation. A freshly built
+// Server can be swapped in at any time: requests already dispatched keep
+// running against the generation they started on, while new requests pick up
+// the replacement.
+type Handle struct {
+	current atomic.Pointer[Server]
+}
+
+// NewHandle constructs a Handle serving the provided generation.
+func NewHandle(srv *Server) *Handle {
+	h := &Handle{}
+	h.current.Store(srv)
+	return h
+}
+
+// Current returns the generation currently serving new requests.
+func (h *Handle) Current() *Server {
+	if h == nil {
+		return nil
+	}
+	return h.current.Load()
+}
+
+// Swap installs srv as the active generation and returns the previous one.
+// Browsers following the previous generation's live-reload stream are told to
+// reload. A nil srv is ignored.
+func (h *Handle) Swap(srv *Server) *Server {
+	if h == nil || srv == nil {
+		return nil
+	}
+	prev := h.current.Swap(srv)
+	prev.retire()
+	return prev
+}
+
+// ServeHTTP satisfies http.Handler.
+func (h *Handle) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv := h.Current()
+	if srv == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	srv.Handler().ServeHTTP(w, r)
+}
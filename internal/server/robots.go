@@ -0,0 +1,75 @@
+package server
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/robots"
+)
+
+// serveRobots generates robots.txt for the environment serving the request.
+// An environment policy wins, then a packed robots.txt, then
+// site.robots_policy; the Sitemap line always points at site.base_url.
+func (s *Server) serveRobots(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		s.writeStatus(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	name, env := s.cfg.Site.EnvironmentFor(r.Host)
+	policy := s.cfg.Site.RobotsPolicyFor(name)
+
+	if !env.NoIndex && env.RobotsPolicy == "" {
+		if asset, err := s.assetCache.Get("robots.txt"); err == nil {
+			policy = string(asset.Body)
+		} else if !errors.Is(err, fs.ErrNotExist) && s.logger != nil {
+			s.logger.Error("robots asset", "error", err)
+		}
+	}
+
+	body, err := robots.Build(s.cfg.Site.BaseURL, policy)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Error("robots build", "error", err)
+		}
+		s.serveError(w, r, http.StatusInternalServerError)
+		return
+	}
+	body = append(body, '\n')
+
+	etag := computeETag(body)
+	header := w.Header()
+	header.Set("Content-Type", "text/plain; charset=utf-8")
+	header.Set("Cache-Control", "public, max-age=300")
+	s.applyHeaderRules(w, r.URL.Path)
+	s.applyCacheHeaders(w, etag, time.Time{})
+
+	if isNotModified(r, etag, time.Time{}) {
+		s.writeStatus(w, http.StatusNotModified)
+		return
+	}
+
+	if r.Method == http.MethodHead {
+		s.writeStatus(w, http.StatusOK)
+		return
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// environmentHeaders marks every response from a noindex environment so
+// staging and preview hosts stay out of search results.
+func (s *Server) environmentHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, env := s.cfg.Site.EnvironmentFor(r.Host); env.NoIndex {
+			w.Header().Set("X-Robots-Tag", "noindex")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
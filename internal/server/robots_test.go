@@ -0,0 +1,69 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+func TestServeRobots(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+	if err := os.Remove(filepath.Join(src.Root(), "robots.txt")); err != nil {
+		t.Fatalf("remove packed robots: %v", err)
+	}
+
+	cfg.Site.RobotsPolicy = "User-agent: *\nDisallow: /admin\nSitemap: https://old.example/sitemap.xml"
+	cfg.Site.Environments = map[string]config.Environment{
+		"staging": {NoIndex: true, Hosts: []string{"staging.example.test"}},
+	}
+
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	get := func(host, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("www.example.test", "/robots.txt")
+	want := "User-agent: *\nDisallow: /admin\nSitemap: https://example.test/sitemap.xml\n"
+	if rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Fatalf("unexpected robots.txt (%d): %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("X-Robots-Tag") != "" {
+		t.Fatalf("production host should not be noindex")
+	}
+
+	rec = get("staging.example.test", "/robots.txt")
+	want = "User-agent: *\nDisallow: /\nSitemap: https://example.test/sitemap.xml\n"
+	if rec.Body.String() != want {
+		t.Fatalf("unexpected staging robots.txt: %q", rec.Body.String())
+	}
+
+	for _, path := range []string{"/", "/robots.txt", "/static/app.css", "/missing"} {
+		if got := get("staging.example.test", path).Header().Get("X-Robots-Tag"); got != "noindex" {
+			t.Fatalf("%s on staging: expected X-Robots-Tag noindex, got %q", path, got)
+		}
+	}
+
+	// A packed robots.txt replaces site.robots_policy but not a noindex
+	// environment, and its Sitemap line is still corrected.
+	mustWrite(t, filepath.Join(src.Root(), "robots.txt"), "User-agent: *\nAllow: /\n")
+	srv.Invalidate([]string{"robots.txt"})
+
+	if body := get("www.example.test", "/robots.txt").Body.String(); body != "User-agent: *\nAllow: /\nSitemap: https://example.test/sitemap.xml\n" {
+		t.Fatalf("packed robots.txt not used: %q", body)
+	}
+	if body := get("staging.example.test", "/robots.txt").Body.String(); body != want {
+		t.Fatalf("noindex environment should override packed robots.txt: %q", body)
+	}
+}
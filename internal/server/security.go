@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/elchemista/LandingGo/internal/config"
+	"github.com/elchemista/LandingGo/internal/csp"
+	"github.com/elchemista/LandingGo/internal/middleware"
+)
+
+// maxCSPReportBytes bounds the size of a violation report body.
+const maxCSPReportBytes = 64 << 10
+
+var cspPlaceholder = []byte(csp.Placeholder)
+
+// securityHeaders returns the hardening headers for HTML responses and the
+// CSP with the nonce placeholder, which is empty when CSP is disabled.
+func securityHeaders(cfg *config.Config) (map[string]string, string) {
+	headers := cfg.Security.Headers.Directives(strings.HasPrefix(cfg.Site.BaseURL, "https://"))
+	if !cfg.Security.CSP.Enabled {
+		return headers, ""
+	}
+	return headers, cfg.Security.CSP.Policy(csp.Placeholder)
+}
+
+// pageNonce is the PageData.Nonce value: the placeholder filled in by
+// fillNonce when CSP is enabled.
+func (s *Server) pageNonce() string {
+	if s.cspPolicy == "" {
+		return ""
+	}
+	return csp.Placeholder
+}
+
+// applySecurityHeaders sets the hardening headers and the CSP on an HTML
+// response. It returns the nonce the policy allows, or "" without CSP.
+func (s *Server) applySecurityHeaders(w http.ResponseWriter) string {
+	header := w.Header()
+	for key, val := range s.secHeaders {
+		header.Set(key, val)
+	}
+	if s.cspPolicy == "" {
+		return ""
+	}
+
+	policy := s.cfg.Security.CSP
+	nonce := csp.NewNonce()
+	header.Set(policy.HeaderName(), strings.ReplaceAll(s.cspPolicy, csp.Placeholder, nonce))
+	if policy.Reporting() {
+		header.Set("Reporting-Endpoints", config.CSPReportGroup+`="`+policy.ReportURI+`"`)
+	}
+	return nonce
+}
+
+// fillNonce replaces the nonce placeholders in a rendered page.
+func fillNonce(body []byte, nonce string) []byte {
+	return bytes.ReplaceAll(body, cspPlaceholder, []byte(nonce))
+}
+
+// markPersonal stops a response that differs per request from being cached
+// or revalidated.
+func markPersonal(w http.ResponseWriter) {
+	header := w.Header()
+	header.Set("Cache-Control", "private, no-store")
+	header.Del("ETag")
+	header.Del("Last-Modified")
+}
+
+// serveCSPReport logs the violations browsers report for the policy.
+func (s *Server) serveCSPReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		s.writeStatus(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCSPReportBytes))
+	if err != nil {
+		s.writeStatus(w, http.StatusBadRequest)
+		return
+	}
+	violations, err := csp.ParseReports(body)
+	if err != nil {
+		s.writeStatus(w, http.StatusBadRequest)
+		return
+	}
+
+	if s.logger != nil {
+		for _, v := range violations {
+			s.logger.Warn("csp violation",
+				"document", v.Document,
+				"directive", v.Directive,
+				"blocked", v.Blocked,
+				"source", v.Source,
+				"line", v.Line,
+				"column", v.Column,
+				"sample", v.Sample,
+				"disposition", v.Disposition,
+				"ip", middleware.ClientIP(r),
+				"request_id", middleware.RequestIDFromContext(r.Context()),
+			)
+		}
+	}
+
+	s.writeStatus(w, http.StatusNoContent)
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/json"
 	"errors"
@@ -9,6 +10,7 @@ import (
 	"log/slog"
 	"net/http"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -16,11 +18,16 @@ import (
 	"github.com/elchemista/LandingGo/internal/assets"
 	"github.com/elchemista/LandingGo/internal/config"
 	"github.com/elchemista/LandingGo/internal/contact"
+	"github.com/elchemista/LandingGo/internal/content"
+	"github.com/elchemista/LandingGo/internal/csp"
+	"github.com/elchemista/LandingGo/internal/csrf"
 	errorspkg "github.com/elchemista/LandingGo/internal/errors"
+	"github.com/elchemista/LandingGo/internal/livereload"
 	"github.com/elchemista/LandingGo/internal/middleware"
+	"github.com/elchemista/LandingGo/internal/outbox"
 	"github.com/elchemista/LandingGo/internal/pages"
 	"github.com/elchemista/LandingGo/internal/router"
-	"github.com/elchemista/LandingGo/internal/sitemap"
+	"github.com/elchemista/LandingGo/internal/spam"
 )
 
 // Server represents the HTTP server runtime.
@@ -34,11 +41,30 @@ type Server struct {
 	handler http.Handler
 
 	pageMgr    *pages.Manager
+	errorPages *errorspkg.Templates
 	assetCache *assets.Cache
 
-	sitemap []byte
+	// sitemaps maps request paths to generated sitemap files. /sitemap.xml
+	// is always present and serves the index when the sitemap is split.
+	sitemaps map[string]*pageEntry
 
-	contact contact.Sender
+	// forms maps endpoint paths to configured forms.
+	forms    map[string]*form
+	outbox   *outbox.Outbox
+	tokens   *spam.Signer
+	limiters *spam.Limiters
+	csrf     *csrf.Protector
+
+	// secHeaders are set on every HTML response; cspPolicy is the CSP with
+	// the nonce placeholder, or "" when CSP is disabled.
+	secHeaders map[string]string
+	cspPolicy  string
+
+	liveReload *livereload.Hub
+
+	dataMu   sync.RWMutex
+	siteData map[string]any // data files merged with site vars
+	entries  map[string]collectionEntry
 
 	pageCache  sync.Map // route path -> *pageEntry
 	errorCache sync.Map // key -> []byte
@@ -49,10 +75,42 @@ type pageEntry struct {
 	Body         []byte
 	ETag         string
 	LastModified time.Time
+	// CSRF is set when Body contains csrfToken placeholders.
+	CSRF bool
+	// Nonce is set when Body contains CSP nonce placeholders.
+	Nonce bool
+}
+
+// Option configures optional Server behaviour.
+type Option func(*Server)
+
+// WithOutbox queues contact submissions in ob instead of sending them while
+// the request waits. The outbox outlives server generations, so the caller
+// owns its worker and shutdown.
+func WithOutbox(ob *outbox.Outbox) Option {
+	return func(s *Server) {
+		s.outbox = ob
+	}
+}
+
+// WithTokenSecret keys the signed form tokens used by the spam time trap.
+// Instances behind the same load balancer need the same secret.
+func WithTokenSecret(secret string) Option {
+	return func(s *Server) {
+		s.tokens = spam.NewSigner(secret)
+	}
+}
+
+// WithLimiters keeps the forms' rate limits in limiters, which should outlive
+// server generations so a configuration reload does not reset them.
+func WithLimiters(limiters *spam.Limiters) Option {
+	return func(s *Server) {
+		s.limiters = limiters
+	}
 }
 
 // New constructs a server instance.
-func New(cfg *config.Config, src *assets.Source, logger *slog.Logger, dev bool) (*Server, error) {
+func New(cfg *config.Config, src *assets.Source, logger *slog.Logger, dev bool, opts ...Option) (*Server, error) {
 	if cfg == nil {
 		return nil, errors.New("config is nil")
 	}
@@ -65,21 +123,29 @@ func New(cfg *config.Config, src *assets.Source, logger *slog.Logger, dev bool)
 		return nil, fmt.Errorf("pages fs: %w", err)
 	}
 
-	pageMgr := pages.New(pagesFS, nil)
+	funcs := pages.Funcs(pages.FuncOptions{
+		BaseURL:  cfg.Site.BaseURL,
+		Manifest: src.Manifest,
+	})
+	pageMgr := pages.New(pagesFS, funcs)
+
+	siteData, err := loadSiteData(cfg, src)
+	if err != nil {
+		return nil, err
+	}
 
 	assetCache := assets.NewCache(src.FS, src.Manifest, src.GeneratedAt, src.ModTime)
 
 	routes := cfg.RoutesByPath()
 
-	sitemapPayload, err := sitemap.Build(cfg.Site.BaseURL, routes, cfg.LoadedAt())
+	entries, err := loadEntries(src, routes, dev)
 	if err != nil {
-		return nil, fmt.Errorf("sitemap build: %w", err)
+		return nil, err
 	}
-	sitemapPayload = append([]byte("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"), sitemapPayload...)
 
-	var contactSender contact.Sender
-	if cfg.Contact.Enabled() {
-		contactSender = contact.NewService(cfg.Contact, nil)
+	sitemaps, err := buildSitemaps(cfg, src, routes, entries)
+	if err != nil {
+		return nil, fmt.Errorf("sitemap build: %w", err)
 	}
 
 	srv := &Server{
@@ -89,9 +155,30 @@ func New(cfg *config.Config, src *assets.Source, logger *slog.Logger, dev bool)
 		dev:        dev,
 		router:     router.New(),
 		pageMgr:    pageMgr,
+		errorPages: errorspkg.New(funcs),
 		assetCache: assetCache,
-		sitemap:    sitemapPayload,
-		contact:    contactSender,
+		sitemaps:   sitemaps,
+		csrf:       csrf.New(cfg.Security.CSRF, cfg.Site.BaseURL),
+		siteData:   siteData,
+		entries:    entries,
+	}
+
+	srv.secHeaders, srv.cspPolicy = securityHeaders(cfg)
+
+	for _, opt := range opts {
+		opt(srv)
+	}
+
+	if srv.tokens == nil {
+		srv.tokens = spam.NewSigner("")
+	}
+	if srv.limiters == nil {
+		srv.limiters = spam.NewLimiters()
+	}
+	srv.forms = buildForms(cfg, srv.tokens, srv.limiters, logger)
+
+	if dev {
+		srv.liveReload = livereload.New()
 	}
 
 	srv.registerRoutes(routes)
@@ -99,39 +186,66 @@ func New(cfg *config.Config, src *assets.Source, logger *slog.Logger, dev bool)
 	srv.handler = middleware.Chain(
 		http.HandlerFunc(srv.router.ServeHTTP),
 		middleware.Gzip(-1),
+		middleware.ResolveClientIP(middleware.NewIPResolver(cfg.Security.TrustedPrefixes(), cfg.Security.TrustsFly())),
 		middleware.Logging(logger),
 		middleware.WithRequestID("X-Request-Id"),
 		middleware.Recover(logger, srv.recoverHandler),
+		srv.environmentHeaders,
 	)
 
 	return srv, nil
 }
 
 func (s *Server) registerRoutes(routes []config.Route) {
-	s.router.Handle("/sitemap.xml", http.HandlerFunc(s.serveSitemap))
+	for name := range s.sitemaps {
+		s.router.Handle(name, http.HandlerFunc(s.serveSitemap))
+	}
 	s.router.Handle("/robots.txt", http.HandlerFunc(s.serveRobots))
 	s.router.Handle("/healthz", http.HandlerFunc(s.serveHealth))
 	s.router.Handle("/favicon.ico", http.HandlerFunc(s.serveFavicon))
 	s.router.HandlePrefix("/static/", http.HandlerFunc(s.serveStatic))
 
-	var contactRoute *config.Route
+	if s.usesFormTokens() {
+		s.router.Handle(formTokenPath, http.HandlerFunc(s.serveFormToken))
+	}
+
+	if s.liveReload != nil {
+		s.router.Handle(livereload.Path, http.HandlerFunc(s.serveLiveReload))
+	}
+
+	if report := s.cfg.Security.CSP.ReportURI; s.cspPolicy != "" && strings.HasPrefix(report, "/") {
+		s.router.Handle(report, http.HandlerFunc(s.serveCSPReport))
+	}
+
+	// A page sharing its path with a form endpoint is served for GET and
+	// HEAD by the form handler.
+	formRoutes := make(map[string]*config.Route, len(s.forms))
 
 	for i := range routes {
 		route := routes[i]
-		if route.Path == "/contact" {
-			contactRoute = &routes[i]
+		if _, ok := s.forms[route.Path]; ok {
+			formRoutes[route.Path] = &routes[i]
 			continue
 		}
 
 		routeCopy := route
+		if route.IsCollection() {
+			s.router.HandlePattern(routeCopy.Path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				s.serveEntry(w, r, routeCopy)
+			}))
+			continue
+		}
 		s.router.Handle(routeCopy.Path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			s.servePage(w, r, routeCopy)
 		}))
 	}
 
-	s.router.Handle("/contact", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		s.serveContact(w, r, contactRoute)
-	}))
+	for path, f := range s.forms {
+		route := formRoutes[path]
+		s.router.Handle(path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.serveContact(w, r, f, route)
+		}))
+	}
 
 	s.router.NotFound(http.HandlerFunc(s.serveNotFound))
 
@@ -142,6 +256,80 @@ func (s *Server) Handler() http.Handler {
 	return s.handler
 }
 
+// Invalidate drops cached templates, rendered pages and assets affected by the
+// changed paths (relative to the asset root) and tells connected browsers to
+// reload. The development file watcher calls it after every change.
+func (s *Server) Invalidate(paths []string) {
+	pagesChanged := false
+
+	for _, p := range paths {
+		p = filepath.ToSlash(p)
+		if strings.HasPrefix(p, pages.DataDir+"/") {
+			s.reloadSiteData()
+			pagesChanged = true
+			continue
+		}
+		if strings.HasPrefix(p, content.Dir+"/") {
+			s.reloadEntries()
+			pagesChanged = true
+			continue
+		}
+		if name, ok := strings.CutPrefix(p, "pages/"); ok {
+			s.pageMgr.Invalidate(name)
+			pagesChanged = true
+			continue
+		}
+		s.assetCache.Invalidate(p)
+	}
+
+	if pagesChanged {
+		clearMap(&s.pageCache)
+		clearMap(&s.errorCache)
+	}
+
+	s.liveReload.Reload()
+}
+
+// Close releases long-lived resources such as live-reload streams.
+func (s *Server) Close() {
+	if s == nil {
+		return
+	}
+	s.liveReload.Close()
+}
+
+// retire is called once a newer generation has replaced s.
+func (s *Server) retire() {
+	if s == nil {
+		return
+	}
+	s.liveReload.Reload()
+	s.liveReload.Close()
+}
+
+func (s *Server) serveLiveReload(w http.ResponseWriter, r *http.Request) {
+	disableCompression(w)
+	s.liveReload.ServeHTTP(w, r)
+}
+
+// decorateHTML applies development-only additions to rendered HTML.
+func (s *Server) decorateHTML(body []byte) []byte {
+	if s.liveReload == nil {
+		return body
+	}
+	if s.cspPolicy != "" {
+		return livereload.InjectNonce(body, csp.Placeholder)
+	}
+	return livereload.Inject(body)
+}
+
+func clearMap(m *sync.Map) {
+	m.Range(func(key, _ any) bool {
+		m.Delete(key)
+		return true
+	})
+}
+
 func (s *Server) servePage(w http.ResponseWriter, r *http.Request, route config.Route) {
 	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		w.Header().Set("Allow", "GET, HEAD")
@@ -149,6 +337,12 @@ func (s *Server) servePage(w http.ResponseWriter, r *http.Request, route config.
 		return
 	}
 
+	route, ok := s.markdownRoute(route)
+	if !ok {
+		s.serveNotFound(w, r)
+		return
+	}
+
 	entry, err := s.loadPage(route)
 	if err != nil {
 		if s.logger != nil {
@@ -160,9 +354,20 @@ func (s *Server) servePage(w http.ResponseWriter, r *http.Request, route config.
 
 	s.applyCacheHeaders(w, entry.ETag, entry.LastModified)
 	s.applyHTMLHeaders(w)
-	s.applyRouteHeaders(w, route.Path)
+	if route.NoIndex {
+		w.Header().Set("X-Robots-Tag", "noindex")
+	}
+	nonce := s.applySecurityHeaders(w)
+	s.applyHeaderRules(w, route.Path)
 
-	if isNotModified(r, entry.ETag, entry.LastModified) {
+	body := entry.Body
+	if entry.Nonce {
+		markPersonal(w)
+		body = fillNonce(body, nonce)
+	}
+	if entry.CSRF {
+		body = s.fillCSRF(w, r, body)
+	} else if !entry.Nonce && isNotModified(r, entry.ETag, entry.LastModified) {
 		s.writeStatus(w, http.StatusNotModified)
 		return
 	}
@@ -173,17 +378,17 @@ func (s *Server) servePage(w http.ResponseWriter, r *http.Request, route config.
 	}
 
 	s.writeStatus(w, http.StatusOK)
-	_, _ = w.Write(entry.Body)
+	_, _ = w.Write(body)
 }
 
-func (s *Server) serveContact(w http.ResponseWriter, r *http.Request, route *config.Route) {
+func (s *Server) serveContact(w http.ResponseWriter, r *http.Request, f *form, route *config.Route) {
 	switch r.Method {
 	case http.MethodPost:
-		s.handleContactSubmit(w, r)
+		s.handleContactSubmit(w, r, f)
 		return
 	case http.MethodGet, http.MethodHead:
 		if route == nil {
-			w.Header().Set("Allow", "POST")
+			// A 404 or redirect, not a 405, so no Allow header.
 			s.serveNotFound(w, r)
 			return
 		}
@@ -200,40 +405,65 @@ func (s *Server) serveContact(w http.ResponseWriter, r *http.Request, route *con
 	}
 }
 
-func (s *Server) handleContactSubmit(w http.ResponseWriter, r *http.Request) {
+// maxContactForm bounds the size of a contact form submission.
+const maxContactForm = 1 << 20
+
+func (s *Server) handleContactSubmit(w http.ResponseWriter, r *http.Request, f *form) {
 	if r.Method != http.MethodPost {
 		w.Header().Set("Allow", "GET, HEAD, POST")
 		s.writeStatus(w, http.StatusMethodNotAllowed)
 		return
 	}
 
-	if err := r.ParseForm(); err != nil {
+	r.Body = http.MaxBytesReader(w, r.Body, maxContactForm)
+	if err := r.ParseMultipartForm(maxContactForm); err != nil && !errors.Is(err, http.ErrNotMultipart) {
 		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid form data"})
 		return
 	}
 
-	name := strings.TrimSpace(r.FormValue("name"))
-	email := strings.TrimSpace(r.FormValue("email"))
-	message := strings.TrimSpace(r.FormValue("message"))
+	// Spam checks come before field validation so a rejected bot, or one
+	// over the rate limit, never sees field errors.
+	if s.rejectForgery(w, r, f) || s.rejectSpam(w, r, f) {
+		return
+	}
 
-	if name == "" || email == "" || message == "" {
-		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name, email, and message are required"})
+	msg, fieldErrs := contact.ParseForm(f.cfg.FormFields(), r.PostForm)
+	if fieldErrs != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid form data", "fields": fieldErrs})
 		return
 	}
 
-	if s.contact == nil || !s.contact.Enabled() {
+	if f.sender == nil || !f.sender.Enabled() {
 		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "contact form disabled"})
 		return
 	}
 
-	err := s.contact.Send(r.Context(), contact.Message{
-		Name:  name,
-		Email: email,
-		Body:  message,
-	})
+	if s.rejectCaptcha(w, r, f) {
+		return
+	}
+	if !f.spam.Consume(r.PostForm, time.Now()) {
+		s.fakeAccept(w, r, f, spam.ReasonReplayed)
+		return
+	}
+
+	msg = f.route(msg)
+
+	if s.outbox != nil {
+		if err := s.outbox.Enqueue(msg); err != nil {
+			if s.logger != nil {
+				s.logger.Error("contact enqueue", "form", f.cfg.Name, "error", err)
+			}
+			s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "failed to queue message"})
+			return
+		}
+		s.writeJSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
+		return
+	}
+
+	err := f.sender.Send(r.Context(), msg)
 	if err != nil {
 		if s.logger != nil {
-			s.logger.Error("contact send", "error", err)
+			s.logger.Error("contact send", "form", f.cfg.Name, "error", err)
 		}
 		s.writeJSON(w, http.StatusBadGateway, map[string]string{"error": "failed to send message"})
 		return
@@ -260,25 +490,14 @@ func (s *Server) serveStatic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	header := w.Header()
-	header.Set("Content-Type", asset.MIME)
-	header.Set("Cache-Control", "public, max-age=31536000, immutable")
-	header.Set("Content-Length", fmt.Sprintf("%d", asset.Size))
-
-	s.applyCacheHeaders(w, asset.ETag, asset.LastModified)
-
-	if isNotModified(r, asset.ETag, asset.LastModified) {
-		s.writeStatus(w, http.StatusNotModified)
-		return
+	// Only content-hashed URLs are safe to cache forever; the un-hashed path
+	// stays available for old references but must be revalidated regularly.
+	cacheControl := "public, max-age=300"
+	if asset.Immutable {
+		cacheControl = "public, max-age=31536000, immutable"
 	}
 
-	if r.Method == http.MethodHead {
-		s.writeStatus(w, http.StatusOK)
-		return
-	}
-
-	s.writeStatus(w, http.StatusOK)
-	_, _ = w.Write(asset.Body)
+	s.writeAsset(w, r, asset, cacheControl)
 }
 
 func (s *Server) serveAssetFromRoot(w http.ResponseWriter, r *http.Request, relPath, cacheControl string) bool {
@@ -305,25 +524,7 @@ func (s *Server) serveAssetFromRoot(w http.ResponseWriter, r *http.Request, relP
 		cacheControl = "public, max-age=300"
 	}
 
-	header := w.Header()
-	header.Set("Content-Type", asset.MIME)
-	header.Set("Cache-Control", cacheControl)
-	header.Set("Content-Length", fmt.Sprintf("%d", asset.Size))
-
-	s.applyCacheHeaders(w, asset.ETag, asset.LastModified)
-
-	if isNotModified(r, asset.ETag, asset.LastModified) {
-		s.writeStatus(w, http.StatusNotModified)
-		return true
-	}
-
-	if r.Method == http.MethodHead {
-		s.writeStatus(w, http.StatusOK)
-		return true
-	}
-
-	s.writeStatus(w, http.StatusOK)
-	_, _ = w.Write(asset.Body)
+	s.writeAsset(w, r, asset, cacheControl)
 	return true
 }
 
@@ -341,25 +542,7 @@ func (s *Server) serveFavicon(w http.ResponseWriter, r *http.Request) {
 	const faviconPath = "static/favicon.ico"
 	if s.assetCache != nil {
 		if asset, err := s.assetCache.Get(faviconPath); err == nil {
-			header := w.Header()
-			header.Set("Content-Type", asset.MIME)
-			header.Set("Cache-Control", "public, max-age=31536000, immutable")
-			header.Set("Content-Length", fmt.Sprintf("%d", asset.Size))
-
-			s.applyCacheHeaders(w, asset.ETag, asset.LastModified)
-
-			if isNotModified(r, asset.ETag, asset.LastModified) {
-				s.writeStatus(w, http.StatusNotModified)
-				return
-			}
-
-			if r.Method == http.MethodHead {
-				s.writeStatus(w, http.StatusOK)
-				return
-			}
-
-			s.writeStatus(w, http.StatusOK)
-			_, _ = w.Write(asset.Body)
+			s.writeAsset(w, r, asset, "public, max-age=31536000, immutable")
 			return
 		} else if s.logger != nil && !errors.Is(err, fs.ErrNotExist) {
 			s.logger.Error("favicon asset", "asset", faviconPath, "error", err)
@@ -374,38 +557,6 @@ func (s *Server) serveFavicon(w http.ResponseWriter, r *http.Request) {
 	s.writeStatus(w, http.StatusOK)
 }
 
-func (s *Server) serveSitemap(w http.ResponseWriter, r *http.Request) {
-	header := w.Header()
-	header.Set("Content-Type", "application/xml")
-	header.Set("Cache-Control", "public, max-age=300")
-	header.Set("Content-Length", fmt.Sprintf("%d", len(s.sitemap)))
-
-	if r.Method == http.MethodHead {
-		s.writeStatus(w, http.StatusOK)
-		return
-	}
-
-	s.writeStatus(w, http.StatusOK)
-	_, _ = w.Write(s.sitemap)
-}
-
-func (s *Server) serveRobots(w http.ResponseWriter, r *http.Request) {
-	if s.serveAssetFromRoot(w, r, "robots.txt", "public, max-age=300") {
-		return
-	}
-
-	if r.Method != http.MethodGet && r.Method != http.MethodHead {
-		return
-	}
-
-	header := w.Header()
-	header.Set("Content-Type", "text/plain; charset=utf-8")
-	header.Set("Cache-Control", "no-store, max-age=0")
-	header.Set("Content-Length", "0")
-
-	s.writeStatus(w, http.StatusNotFound)
-}
-
 func (s *Server) serveHealth(w http.ResponseWriter, r *http.Request) {
 	health := []byte(`{"status":"ok"}`)
 	header := w.Header()
@@ -448,16 +599,24 @@ func (s *Server) serveNotFound(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.writeErrorPage(w, r, "404.html", errorspkg.Default404, http.StatusNotFound)
+	if s.serveRedirect(w, r) {
+		return
+	}
+
+	s.writeErrorPage(w, r, "404.html", s.errorPages.Render404, http.StatusNotFound)
 }
 
 func (s *Server) serveError(w http.ResponseWriter, r *http.Request, status int) {
-	if status == http.StatusInternalServerError {
-		s.writeErrorPage(w, r, "500.html", errorspkg.Default500, status)
+	switch status {
+	case http.StatusInternalServerError:
+		s.writeErrorPage(w, r, "500.html", s.errorPages.Render500, status)
+		return
+	case http.StatusForbidden:
+		s.writeErrorPage(w, r, "403.html", s.errorPages.Render403, status)
 		return
 	}
 
-	s.writeErrorPage(w, r, "404.html", errorspkg.Default404, status)
+	s.writeErrorPage(w, r, "404.html", s.errorPages.Render404, status)
 }
 
 func (s *Server) recoverHandler(w http.ResponseWriter, r *http.Request, rec any) {
@@ -484,6 +643,9 @@ func (s *Server) writeErrorPage(w http.ResponseWriter, r *http.Request, pageName
 		body = fallback(data)
 	}
 
+	body = s.decorateHTML(body)
+	body = fillNonce(body, s.applySecurityHeaders(w))
+
 	header := w.Header()
 	header.Set("Content-Type", "text/html; charset=utf-8")
 	header.Set("Cache-Control", "no-store, max-age=0")
@@ -511,39 +673,131 @@ func (s *Server) basePageData(status int, path string) pages.PageData {
 		BaseURL:    s.cfg.Site.BaseURL,
 		NowRFC3339: s.cfg.LoadedAt().Format(time.RFC3339),
 		RoutePath:  path,
+		Extra:      s.pageExtra(nil),
+		Nonce:      s.pageNonce(),
 	}
 }
 
+// routePageData builds the template context for a configured route.
+func (s *Server) routePageData(route config.Route) pages.PageData {
+	base := s.cfg.Site.BaseURL
+
+	canonical := route.Canonical
+	if canonical == "" {
+		canonical = route.Path
+	}
+
+	data := pages.PageData{
+		Title:       route.Title,
+		BaseURL:     base,
+		NowRFC3339:  s.cfg.LoadedAt().Format(time.RFC3339),
+		RoutePath:   route.Path,
+		Extra:       s.pageExtra(route.Data),
+		Description: route.Description,
+		Canonical:   pages.AbsURL(base, canonical),
+		NoIndex:     route.NoIndex,
+		Lang:        route.Lang,
+		Nonce:       s.pageNonce(),
+	}
+
+	if ce, ok := s.entry(route.Path); ok {
+		data.Entry, data.Content = entryData(ce.entry)
+	}
+
+	if route.OGImage != "" {
+		data.OGImage = pages.AbsURL(base, pages.AssetURL(s.source.Manifest, route.OGImage))
+	}
+
+	for _, hreflang := range sortedKeys(route.Alternates) {
+		data.Alternates = append(data.Alternates, pages.Alternate{
+			Hreflang: hreflang,
+			URL:      pages.AbsURL(base, route.Alternates[hreflang]),
+		})
+	}
+
+	return data
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// loadSiteData reads data files from the asset source and layers the
+// configured site vars over them.
+func loadSiteData(cfg *config.Config, src *assets.Source) (map[string]any, error) {
+	var dataFS fs.FS
+	if src.Exists(pages.DataDir) {
+		sub, err := src.Sub(pages.DataDir)
+		if err != nil {
+			return nil, fmt.Errorf("data fs: %w", err)
+		}
+		dataFS = sub
+	}
+
+	files, err := pages.LoadData(dataFS)
+	if err != nil {
+		return nil, err
+	}
+
+	return pages.MergeData(files, cfg.Site.Vars), nil
+}
+
+// reloadSiteData re-reads data files after a change in dev mode. A broken
+// file keeps the previous data so the page stays viewable while editing.
+func (s *Server) reloadSiteData() {
+	data, err := loadSiteData(s.cfg, s.source)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Error("reload data files", "error", err)
+		}
+		return
+	}
+
+	s.dataMu.Lock()
+	s.siteData = data
+	s.dataMu.Unlock()
+}
+
+// pageExtra builds PageData.Extra: route data over site vars over data files.
+func (s *Server) pageExtra(routeData map[string]any) map[string]any {
+	s.dataMu.RLock()
+	defer s.dataMu.RUnlock()
+	return pages.MergeData(s.siteData, routeData)
+}
+
 func (s *Server) loadPage(route config.Route) (*pageEntry, error) {
 	if entry, ok := s.pageCache.Load(route.Path); ok {
 		return entry.(*pageEntry), nil
 	}
 
-	body, err := s.pageMgr.Render(route.Page, pages.PageData{
-		Title:      route.Title,
-		BaseURL:    s.cfg.Site.BaseURL,
-		NowRFC3339: s.cfg.LoadedAt().Format(time.RFC3339),
-		RoutePath:  route.Path,
-	})
+	body, err := s.pageMgr.Render(route.Page, s.routePageData(route))
 	if err != nil {
 		return nil, err
 	}
 
-	entry := &pageEntry{Body: body}
+	body = s.decorateHTML(body)
+
+	// The ETag hashes the rendered output: the same page file renders
+	// differently depending on its layout, partials and template data.
+	entry := &pageEntry{
+		Body:  body,
+		ETag:  computeETag(body),
+		CSRF:  bytes.Contains(body, csrfPlaceholder),
+		Nonce: bytes.Contains(body, cspPlaceholder),
+	}
 
 	if s.source.Manifest != nil {
 		manifestPath := filepath.ToSlash(filepath.Join("pages", route.Page))
-		if meta, ok := s.source.Manifest.Files[manifestPath]; ok {
-			entry.ETag = ensureQuoted(meta.SHA256)
-			if !meta.ModTime.IsZero() {
-				entry.LastModified = meta.ModTime.UTC()
-			}
+		if meta, ok := s.source.Manifest.Files[manifestPath]; ok && !meta.ModTime.IsZero() {
+			entry.LastModified = meta.ModTime.UTC()
 		}
 	}
 
-	if entry.ETag == "" {
-		entry.ETag = computeETag(body)
-	}
 	if entry.LastModified.IsZero() {
 		if mt, err := s.source.ModTime(filepath.ToSlash(filepath.Join("pages", route.Page))); err == nil {
 			entry.LastModified = mt.UTC()
@@ -552,6 +806,12 @@ func (s *Server) loadPage(route config.Route) (*pageEntry, error) {
 		}
 	}
 
+	if ce, ok := s.entry(route.Path); ok {
+		if mod := fileModTime(s.source, ce.entry.File); mod.After(entry.LastModified) {
+			entry.LastModified = mod
+		}
+	}
+
 	s.pageCache.Store(route.Path, entry)
 
 	return entry, nil
@@ -575,9 +835,14 @@ func (s *Server) applyHTMLHeaders(w http.ResponseWriter) {
 	}
 }
 
-func (s *Server) applyRouteHeaders(w http.ResponseWriter, path string) {
+// applyHeaderRules sets and removes the headers configured for path.
+func (s *Server) applyHeaderRules(w http.ResponseWriter, path string) {
 	header := w.Header()
 	for key, val := range s.cfg.HeaderDirectives(path) {
+		if name, ok := strings.CutPrefix(key, "-"); ok {
+			header.Del(name)
+			continue
+		}
 		header.Set(key, val)
 	}
 }
@@ -610,16 +875,6 @@ func (s *Server) writeJSON(w http.ResponseWriter, status int, payload any) {
 	_, _ = w.Write(data)
 }
 
-func ensureQuoted(hash string) string {
-	if hash == "" {
-		return ""
-	}
-	if strings.HasPrefix(hash, "\"") {
-		return hash
-	}
-	return fmt.Sprintf("\"%s\"", hash)
-}
-
 func computeETag(body []byte) string {
 	sum := sha256.Sum256(body)
 	return fmt.Sprintf("\"%x\"", sum[:])
@@ -1,20 +1,31 @@
 package server
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/elchemista/LandingGo/internal/assets"
+	"github.com/elchemista/LandingGo/internal/assets/packer"
 	"github.com/elchemista/LandingGo/internal/config"
 	"github.com/elchemista/LandingGo/internal/contact"
+	"github.com/elchemista/LandingGo/internal/livereload"
+	"github.com/elchemista/LandingGo/internal/outbox"
+	"github.com/elchemista/LandingGo/internal/spam"
 )
 
 func TestServerHandlers(t *testing.T) {
@@ -153,6 +164,549 @@ func TestServerHandlers(t *testing.T) {
 	})
 }
 
+func TestHandleSwap(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+
+	first, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	handle := NewHandle(first)
+	ts := httptest.NewServer(handle)
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL + "/about")
+	if err != nil {
+		t.Fatalf("get /about: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 before reload, got %d", resp.StatusCode)
+	}
+
+	mustWrite(t, filepath.Join(src.Root(), "pages", "about.html"), `<!doctype html><html><body><h1>{{.Title}}</h1></body></html>`)
+
+	next := &config.Config{
+		Site: cfg.Site,
+		Routes: []config.Route{
+			{Path: "/", Page: "home.html", Title: "Home"},
+			{Path: "/about", Page: "about.html", Title: "About"},
+		},
+	}
+	next.WithLoadedTime(time.Now())
+	if err := next.Validate(src.PageExists); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	second, err := New(next, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	if prev := handle.Swap(second); prev != first {
+		t.Fatalf("expected swap to return previous generation")
+	}
+
+	resp, err = http.Get(ts.URL + "/about")
+	if err != nil {
+		t.Fatalf("get /about: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "About") {
+		t.Fatalf("expected reloaded route, got %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestHandleSwapReloadsConnectedBrowsers(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+
+	first, err := New(cfg, src, nil, true)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	handle := NewHandle(first)
+	ts := httptest.NewServer(handle)
+	t.Cleanup(ts.Close)
+
+	// Several generations and clients, since a lost event depended on which
+	// ready select case was picked.
+	for gen := 0; gen < 5; gen++ {
+		var streams []*bufio.Reader
+		for i := 0; i < 4; i++ {
+			stream, err := http.Get(ts.URL + livereload.Path)
+			if err != nil {
+				t.Fatalf("subscribe: %v", err)
+			}
+			defer stream.Body.Close()
+
+			events := bufio.NewReader(stream.Body)
+			if line, _ := events.ReadString('\n'); !strings.HasPrefix(line, ": connected") {
+				t.Fatalf("unexpected stream preamble: %q", line)
+			}
+			streams = append(streams, events)
+		}
+
+		next, err := New(cfg, src, nil, true)
+		if err != nil {
+			t.Fatalf("new server: %v", err)
+		}
+		handle.Swap(next)
+
+		for i, events := range streams {
+			for {
+				line, err := events.ReadString('\n')
+				if err != nil {
+					t.Fatalf("generation %d client %d: stream ended without a reload event: %v", gen, i, err)
+				}
+				if strings.HasPrefix(line, "event: reload") {
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestDevInvalidateAndLiveReload(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+
+	srv, err := New(cfg, src, nil, true)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	t.Cleanup(srv.Close)
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	body := getBody(t, ts.URL+"/")
+	if !strings.Contains(body, livereload.Path) {
+		t.Fatalf("expected live-reload snippet in dev page: %s", body)
+	}
+
+	stream, err := http.Get(ts.URL + livereload.Path)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer stream.Body.Close()
+
+	if ct := stream.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected stream content type: %s", ct)
+	}
+
+	events := bufio.NewReader(stream.Body)
+	if line, _ := events.ReadString('\n'); !strings.HasPrefix(line, ": connected") {
+		t.Fatalf("unexpected stream preamble: %q", line)
+	}
+
+	mustWrite(t, filepath.Join(src.Root(), "pages", "home.html"), `<!doctype html><html><body><h1>Updated</h1></body></html>`)
+	srv.Invalidate([]string{"pages/home.html"})
+
+	for {
+		line, err := events.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		if strings.HasPrefix(line, "event: reload") {
+			break
+		}
+	}
+
+	if body := getBody(t, ts.URL+"/"); !strings.Contains(body, "Updated") {
+		t.Fatalf("expected invalidated page to be re-rendered, got %s", body)
+	}
+}
+
+func TestPageExtraData(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+	root := src.Root()
+
+	mustWrite(t, filepath.Join(root, "data", "brand.json"), `{"name":"Data","color":"blue","since":2001}`)
+	mustWrite(t, filepath.Join(root, "pages", "campaign.html"), `{{.Extra.brand.name}}|{{.Extra.brand.color}}|{{.Extra.brand.since}}|{{.Extra.headline}}`)
+
+	cfg.Site.Vars = map[string]any{
+		"brand":    map[string]any{"name": "Site"},
+		"headline": "Welcome",
+	}
+	cfg.Routes = append(cfg.Routes,
+		config.Route{Path: "/acme", Page: "campaign.html", Data: map[string]any{"brand": map[string]any{"color": "red"}, "headline": "Hello Acme"}},
+		config.Route{Path: "/globex", Page: "campaign.html"},
+	)
+
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	if body := getBody(t, ts.URL+"/acme"); body != "Site|red|2001|Hello Acme" {
+		t.Fatalf("unexpected /acme body: %q", body)
+	}
+	if body := getBody(t, ts.URL+"/globex"); body != "Site|blue|2001|Welcome" {
+		t.Fatalf("unexpected /globex body: %q", body)
+	}
+
+	mustWrite(t, filepath.Join(root, "data", "brand.json"), `{"name":"Data","color":"green"}`)
+	srv.Invalidate([]string{"data/brand.json"})
+
+	if body := getBody(t, ts.URL+"/globex"); body != "Site|green||Welcome" {
+		t.Fatalf("data file change not picked up: %q", body)
+	}
+
+	mustWrite(t, filepath.Join(root, "data", "broken.json"), `{`)
+	if _, err := New(cfg, src, nil, false); err == nil {
+		t.Fatal("expected invalid data file to be rejected")
+	}
+}
+
+func TestCollectionRoutes(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+	root := src.Root()
+
+	mustWrite(t, filepath.Join(root, "pages", "case.html"), `{{.Title}}|{{.Entry.slug}}|{{.Entry.client}}|{{.Content}}`)
+	mustWrite(t, filepath.Join(root, "content", "work", "acme.md"), "---\ntitle: Acme rebrand\nclient: Acme\n---\n**Bold** work.\n")
+	mustWrite(t, filepath.Join(root, "content", "work", "globex.json"), `{"title": "Globex", "client": "Globex", "noindex": true}`)
+
+	cfg.Routes = append(cfg.Routes, config.Route{Path: "/work/{slug}", Page: "case.html", Title: "Work", Collection: "work"})
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	srv, err := New(cfg, src, nil, true)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	if body := getBody(t, ts.URL+"/work/acme"); !strings.HasPrefix(body, "Acme rebrand|acme|Acme|<p><strong>Bold</strong> work.</p>") {
+		t.Fatalf("unexpected /work/acme body: %q", body)
+	}
+
+	resp, err := http.Get(ts.URL + "/work/initech")
+	if err != nil {
+		t.Fatalf("get unknown entry: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown slug, got %d", resp.StatusCode)
+	}
+
+	sitemap := getBody(t, ts.URL+"/sitemap.xml")
+	if !strings.Contains(sitemap, "https://example.test/work/acme") {
+		t.Fatalf("entry missing from sitemap: %s", sitemap)
+	}
+	if strings.Contains(sitemap, "globex") || strings.Contains(sitemap, "{slug}") {
+		t.Fatalf("noindex entry or pattern listed in sitemap: %s", sitemap)
+	}
+
+	mustWrite(t, filepath.Join(root, "content", "work", "initech.json"), `{"title": "Initech"}`)
+	srv.Invalidate([]string{"content/work/initech.json"})
+
+	if body := getBody(t, ts.URL+"/work/initech"); !strings.HasPrefix(body, "Initech|initech||") {
+		t.Fatalf("new entry not picked up: %q", body)
+	}
+
+	mustWrite(t, filepath.Join(root, "content", "work", "home.json"), `{"slug": "acme"}`)
+	if _, err := New(cfg, src, nil, false); err == nil || !strings.Contains(err.Error(), "already used") {
+		t.Fatalf("expected duplicate slug to be rejected, got %v", err)
+	}
+}
+
+func TestMarkdownPages(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+	root := src.Root()
+
+	mustWrite(t, filepath.Join(root, "pages", "_layouts", "base.html"), `<title>{{.Title}}</title><meta name="description" content="{{.Description}}">{{block "content" .}}{{end}}`)
+	mustWrite(t, filepath.Join(root, "pages", "pricing.md"), "---\ntitle: Pricing\ndescription: Plans for every team.\n---\n| Plan | Price |\n| --- | --- |\n| Pro | 19 |\n")
+	mustWrite(t, filepath.Join(root, "pages", "launch.md"), "---\ntitle: Launch\ndraft: true\n---\nComing soon.\n")
+	mustWrite(t, filepath.Join(root, "pages", "case.html"), `{{.Title}}`)
+	mustWrite(t, filepath.Join(root, "content", "work", "secret.json"), `{"title": "Secret", "draft": true}`)
+
+	cfg.Routes = append(cfg.Routes,
+		config.Route{Path: "/pricing", Page: "pricing.md", Title: "Route title"},
+		config.Route{Path: "/launch", Page: "launch.md"},
+		config.Route{Path: "/work/{slug}", Page: "case.html", Collection: "work"},
+	)
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	body := getBody(t, ts.URL+"/pricing")
+	for _, want := range []string{"<title>Pricing</title>", `content="Plans for every team."`, "<td>Pro</td>"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in /pricing, got %s", want, body)
+		}
+	}
+
+	for _, path := range []string{"/launch", "/work/secret"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("get %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("expected draft %s to be hidden, got %d", path, resp.StatusCode)
+		}
+	}
+
+	sitemap := getBody(t, ts.URL+"/sitemap.xml")
+	if !strings.Contains(sitemap, "https://example.test/pricing") || strings.Contains(sitemap, "launch") || strings.Contains(sitemap, "secret") {
+		t.Fatalf("unexpected sitemap: %s", sitemap)
+	}
+
+	devSrv, err := New(cfg, src, nil, true)
+	if err != nil {
+		t.Fatalf("new dev server: %v", err)
+	}
+	devTS := httptest.NewServer(devSrv.Handler())
+	t.Cleanup(devTS.Close)
+
+	if body := getBody(t, devTS.URL+"/launch"); !strings.Contains(body, "<p>Coming soon.</p>") {
+		t.Fatalf("expected draft to render in dev mode, got %s", body)
+	}
+	if body := getBody(t, devTS.URL+"/work/secret"); !strings.HasPrefix(body, "Secret") {
+		t.Fatalf("expected draft entry to render in dev mode, got %s", body)
+	}
+	if sitemap := getBody(t, devTS.URL+"/sitemap.xml"); strings.Contains(sitemap, "launch") || strings.Contains(sitemap, "secret") {
+		t.Fatalf("drafts listed in dev sitemap: %s", sitemap)
+	}
+
+	mustWrite(t, filepath.Join(root, "pages", "launch.md"), "---\ntitle: Launched\n---\nLive now.\n")
+	devSrv.Invalidate([]string{"pages/launch.md"})
+	if body := getBody(t, devTS.URL+"/launch"); !strings.Contains(body, "<title>Launched</title>") {
+		t.Fatalf("front matter change not picked up: %s", body)
+	}
+
+	// Broken front matter fails construction instead of silently leaving the
+	// page out of the sitemap.
+	mustWrite(t, filepath.Join(root, "pages", "pricing.md"), "---\ntitle: Pricing\n")
+	if _, err := New(cfg, src, nil, false); err == nil || !strings.Contains(err.Error(), "route /pricing") {
+		t.Fatalf("expected broken Markdown page to be reported, got %v", err)
+	}
+}
+
+func TestRouteSEOMetadata(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+	root := src.Root()
+
+	mustWrite(t, filepath.Join(root, "pages", "seo.html"), `{{.Lang}}|{{.Description}}|{{.Canonical}}|{{.OGImage}}|{{.NoIndex}}|{{range .Alternates}}{{.Hreflang}}={{.URL}};{{end}}`)
+	mustWrite(t, filepath.Join(root, "static", "og.png"), "PNG")
+
+	cfg.Routes = append(cfg.Routes,
+		config.Route{
+			Path:        "/it",
+			Page:        "seo.html",
+			Description: "Benvenuti",
+			OGImage:     "/static/og.png",
+			Lang:        "it",
+			Alternates:  map[string]string{"x-default": "/", "en": "/"},
+		},
+		config.Route{Path: "/draft", Page: "seo.html", NoIndex: true, Canonical: "/it"},
+	)
+
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	want := "it|Benvenuti|https://example.test/it|https://example.test/static/og.png|false|en=https://example.test/;x-default=https://example.test/;"
+	if body := getBody(t, ts.URL+"/it"); body != want {
+		t.Fatalf("unexpected SEO data:\n got %q\nwant %q", body, want)
+	}
+
+	resp, err := http.Get(ts.URL + "/draft")
+	if err != nil {
+		t.Fatalf("get /draft: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if got := resp.Header.Get("X-Robots-Tag"); got != "noindex" {
+		t.Fatalf("expected X-Robots-Tag noindex, got %q", got)
+	}
+	if !strings.Contains(string(body), "|https://example.test/it|") || !strings.Contains(string(body), "|true|") {
+		t.Fatalf("unexpected noindex page data: %s", body)
+	}
+
+	if sm := getBody(t, ts.URL+"/sitemap.xml"); strings.Contains(sm, "/draft") {
+		t.Fatalf("noindex route listed in sitemap: %s", sm)
+	}
+}
+
+func TestFingerprintedStatic(t *testing.T) {
+	tdir := t.TempDir()
+	webDir := filepath.Join(tdir, "web")
+	buildDir := filepath.Join(tdir, "build")
+
+	mustWrite(t, filepath.Join(webDir, "pages", "home.html"), `<!doctype html><html><head><link rel="stylesheet" href="/static/app.css"></head><body></body></html>`)
+	mustWrite(t, filepath.Join(webDir, "static", "app.css"), "body { color: #000; }")
+	configPath := filepath.Join(tdir, "config.json")
+	mustWrite(t, configPath, `{"site":{"base_url":"https://example.test"},"routes":[{"path":"/","page":"home.html"}]}`)
+
+	if err := packer.Run(configPath, webDir, buildDir); err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+
+	src, err := assets.NewDisk(filepath.Join(buildDir, "public"))
+	if err != nil {
+		t.Fatalf("new disk source: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if err := cfg.Validate(src.PageExists); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	hashed, ok := src.Manifest.Fingerprint("static/app.css")
+	if !ok {
+		t.Fatalf("manifest missing fingerprint")
+	}
+
+	if page := getBody(t, ts.URL+"/"); !strings.Contains(page, "/"+hashed) {
+		t.Fatalf("page does not reference fingerprinted asset: %s", page)
+	}
+
+	cases := []struct {
+		path         string
+		cacheControl string
+	}{
+		{path: "/" + hashed, cacheControl: "public, max-age=31536000, immutable"},
+		{path: "/static/app.css", cacheControl: "public, max-age=300"},
+	}
+
+	for _, tc := range cases {
+		resp, err := http.Get(ts.URL + tc.path)
+		if err != nil {
+			t.Fatalf("get %s: %v", tc.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", tc.path, resp.StatusCode)
+		}
+		if cc := resp.Header.Get("Cache-Control"); cc != tc.cacheControl {
+			t.Fatalf("%s: unexpected cache-control %q", tc.path, cc)
+		}
+		if string(body) != "body { color: #000; }" {
+			t.Fatalf("%s: unexpected body %q", tc.path, body)
+		}
+	}
+}
+
+func TestPrecompressedStatic(t *testing.T) {
+	tdir := t.TempDir()
+	webDir := filepath.Join(tdir, "web")
+	buildDir := filepath.Join(tdir, "build")
+
+	css := strings.Repeat(".button { color: #123456; padding: 4px 8px; }\n", 64)
+
+	mustWrite(t, filepath.Join(webDir, "pages", "home.html"), `<!doctype html><html><head><link rel="stylesheet" href="/static/app.css"></head><body></body></html>`)
+	mustWrite(t, filepath.Join(webDir, "static", "app.css"), css)
+	configPath := filepath.Join(tdir, "config.json")
+	mustWrite(t, configPath, `{"site":{"base_url":"https://example.test"},"routes":[{"path":"/","page":"home.html"}]}`)
+
+	if err := packer.Run(configPath, webDir, buildDir); err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+
+	src, err := assets.NewDisk(filepath.Join(buildDir, "public"))
+	if err != nil {
+		t.Fatalf("new disk source: %v", err)
+	}
+
+	entry := src.Manifest.Files["static/app.css"]
+	if entry.Gzip == nil || entry.Gzip.Size >= entry.Size {
+		t.Fatalf("expected smaller gzip variant in manifest: %+v", entry)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if err := cfg.Validate(src.PageExists); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	get := func(encoding string) (*http.Response, []byte) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/static/app.css", nil)
+		req.Header.Set("Accept-Encoding", encoding)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, body
+	}
+
+	gzResp, gzBody := get("br;q=1, gzip;q=0.8")
+	if gzResp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected precompressed response, headers: %v", gzResp.Header)
+	}
+	if got := gzResp.Header.Get("Content-Length"); got != strconv.FormatInt(entry.Gzip.Size, 10) || len(gzBody) != int(entry.Gzip.Size) {
+		t.Fatalf("unexpected gzip length %s (%d bytes)", got, len(gzBody))
+	}
+	if vary := gzResp.Header.Get("Vary"); vary != "Accept-Encoding" {
+		t.Fatalf("unexpected Vary: %q", vary)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(gzBody))
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	plain, _ := io.ReadAll(zr)
+	if string(plain) != css {
+		t.Fatalf("precompressed body does not match source")
+	}
+
+	idResp, idBody := get("gzip;q=0, identity")
+	if idResp.Header.Get("Content-Encoding") != "" || string(idBody) != css {
+		t.Fatalf("expected identity response, got encoding %q", idResp.Header.Get("Content-Encoding"))
+	}
+
+	if gzResp.Header.Get("ETag") == idResp.Header.Get("ETag") {
+		t.Fatalf("expected distinct ETags for encoded and identity representations")
+	}
+}
+
 func TestContactSubmit(t *testing.T) {
 	tdir := t.TempDir()
 	webDir := filepath.Join(tdir, "web")
@@ -190,7 +744,7 @@ func TestContactSubmit(t *testing.T) {
 	}
 
 	fake := &fakeContactSender{enabled: true}
-	srv.contact = fake
+	srv.forms["/contact"].sender = fake
 
 	ts := httptest.NewServer(srv.Handler())
 	t.Cleanup(ts.Close)
@@ -218,6 +772,584 @@ func TestContactSubmit(t *testing.T) {
 	}
 }
 
+func TestContactSubmitFieldErrors(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+	cfg.Contact.Fields = []config.Field{
+		{Name: "email", Label: "Email", Type: config.FieldEmail, Required: true},
+		{Name: "company", Label: "Company", Required: true},
+		{Name: "budget", Label: "Budget", Type: config.FieldSelect, Options: []string{"small", "large"}},
+	}
+
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	fake := &fakeContactSender{enabled: true}
+	srv.forms["/contact"].sender = fake
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(url.Values{"email": {"nope"}, "budget": {"huge"}})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	var payload struct {
+		Error  string            `json:"error"`
+		Fields map[string]string `json:"fields"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if payload.Fields["email"] == "" || payload.Fields["company"] != "is required" || payload.Fields["budget"] == "" {
+		t.Fatalf("unexpected field errors: %+v", payload)
+	}
+
+	rec = post(url.Values{"email": {"jane@example.test"}, "company": {"Acme"}, "budget": {"large"}})
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d %s", rec.Code, rec.Body.String())
+	}
+	if len(fake.messages) != 1 || len(fake.messages[0].Fields) != 3 || fake.messages[0].Fields[1].Value != "Acme" {
+		t.Fatalf("unexpected delivered message: %+v", fake.messages)
+	}
+}
+
+func TestContactSubmitQueuesInOutbox(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+
+	fake := &fakeContactSender{enabled: true}
+	ob, err := outbox.Open(t.TempDir(), func(string) contact.Sender { return fake }, outbox.Options{})
+	if err != nil {
+		t.Fatalf("open outbox: %v", err)
+	}
+
+	srv, err := New(cfg, src, nil, false, WithOutbox(ob))
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	srv.forms["/contact"].sender = fake
+
+	form := url.Values{"name": {"Jane"}, "email": {"jane@example.test"}, "message": {"Hello"}}
+	req := httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted || !strings.Contains(rec.Body.String(), "queued") {
+		t.Fatalf("expected 202 queued, got %d %s", rec.Code, rec.Body.String())
+	}
+	if len(fake.messages) != 0 || ob.Pending() != 1 {
+		t.Fatalf("expected message to be queued, not sent: sent=%d pending=%d", len(fake.messages), ob.Pending())
+	}
+
+	if err := ob.Drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if len(fake.messages) != 1 || fake.messages[0].Name != "Jane" {
+		t.Fatalf("expected queued message to be delivered on drain, got %+v", fake.messages)
+	}
+}
+
+func TestNamedFormRouting(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+	cfg.Forms = map[string]config.Form{
+		"quote": {
+			Contact: config.Contact{
+				Backend:   config.BackendWebhook,
+				Webhook:   config.Webhook{URL: "https://hooks.example.test/quote", Secret: "s3cret"},
+				Recipient: "quotes@example.test",
+				Subject:   "Quote: {{.Fields.department}}",
+				Fields: []config.Field{
+					{Name: "email", Type: config.FieldEmail, Required: true},
+					{Name: "department", Type: config.FieldSelect, Options: []string{"sales", "support"}},
+				},
+			},
+			Rules: []config.FormRule{{Field: "department", Equals: "sales", To: "sales@example.test", CC: []string{"owner@example.test"}}},
+		},
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	if srv.Sender("quote") == nil || srv.Sender("contact") != nil {
+		t.Fatalf("unexpected senders: quote=%v contact=%v", srv.Sender("quote"), srv.Sender("contact"))
+	}
+	fake := &fakeContactSender{enabled: true}
+	srv.forms["/forms/quote"].sender = fake
+
+	form := url.Values{"email": {"jane@example.test"}, "department": {"sales"}}
+	req := httptest.NewRequest(http.MethodPost, "/forms/quote", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d %s", rec.Code, rec.Body.String())
+	}
+	msg := fake.messages[0]
+	if msg.Form != "quote" || msg.Subject != "Quote: sales" || msg.To != "sales@example.test" || len(msg.CC) != 1 {
+		t.Fatalf("unexpected routed message: %+v", msg)
+	}
+
+	// The default contact endpoint stays registered but refuses submissions.
+	form = url.Values{"name": {"Jane"}, "email": {"jane@example.test"}, "message": {"Hi"}}
+	req = httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 for unconfigured contact form, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/forms/quote", nil))
+	if rec.Code != http.StatusNotFound || rec.Header().Get("Allow") != "" {
+		t.Fatalf("expected plain 404 for GET on a form endpoint, got %d %q", rec.Code, rec.Header().Get("Allow"))
+	}
+}
+
+func TestUnconfiguredFormBackendIsLogged(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+	cfg.Forms = map[string]config.Form{
+		"quote": {Contact: config.Contact{Backend: config.BackendWebhook, Webhook: config.Webhook{URL: "https://hooks.example.test/quote"}}},
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	var logs bytes.Buffer
+	srv, err := New(cfg, src, slog.New(slog.NewTextHandler(&logs, nil)), false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	if srv.Sender("quote") != nil {
+		t.Fatal("webhook form without a secret should have no sender")
+	}
+	if out := logs.String(); !strings.Contains(out, "form delivery disabled") || !strings.Contains(out, "form=quote") || !strings.Contains(out, "backend=webhook") {
+		t.Fatalf("expected a warning for the quote form, got %q", out)
+	}
+}
+
+func TestContactSubmitSpamLooksSuccessful(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+	cfg.Contact = config.Contact{
+		Backend: config.BackendWebhook,
+		Webhook: config.Webhook{URL: "https://hooks.example.test", Secret: "s3cret"},
+		Spam: config.Spam{
+			Honeypot:   "company",
+			MinSeconds: 2,
+			RateLimit:  config.RateLimit{PerMinute: 1, Burst: 3},
+		},
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	limiters := spam.NewLimiters()
+	srv, err := New(cfg, src, nil, false, WithTokenSecret("token-secret"), WithLimiters(limiters))
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	fake := &fakeContactSender{enabled: true}
+	srv.forms["/contact"].sender = fake
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/form-token", nil))
+	var issued struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &issued); err != nil || issued.Token == "" {
+		t.Fatalf("expected a form token, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = "203.0.113.7:4000"
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+	base := url.Values{"name": {"Bot"}, "email": {"bot@example.test"}, "message": {"Hi"}}
+
+	// Too fast: the token was issued just now.
+	withToken := url.Values{"_token": {issued.Token}}
+	for k, v := range base {
+		withToken[k] = v
+	}
+	if rec := post(withToken); rec.Code != http.StatusAccepted || !strings.Contains(rec.Body.String(), "sent") {
+		t.Fatalf("expected fake success, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	// Honeypot filled, with a token old enough to pass the time trap.
+	old := srv.tokens.Issue(time.Now().Add(-time.Minute))
+	honeypot := url.Values{"_token": {old}, "company": {"Acme"}}
+	for k, v := range base {
+		honeypot[k] = v
+	}
+	if rec := post(honeypot); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected fake success, got %d", rec.Code)
+	}
+	if len(fake.messages) != 0 {
+		t.Fatalf("spam should not be delivered: %+v", fake.messages)
+	}
+
+	// The third, clean submission is delivered; the fourth exceeds the
+	// burst of three and is dropped.
+	honeypot.Del("company")
+	if rec := post(honeypot); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+	honeypot.Set("_token", srv.tokens.Issue(time.Now().Add(-time.Minute)))
+	if rec := post(honeypot); rec.Code != http.StatusAccepted || len(fake.messages) != 1 {
+		t.Fatalf("expected rate-limited submission to be dropped, got %d and %d messages", rec.Code, len(fake.messages))
+	}
+
+	// Invalid fields do not reveal the rejection either.
+	invalid := url.Values{"_token": {old}, "company": {"Acme"}}
+	if rec := post(invalid); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected fake success for invalid spam, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	// A configuration reload keeps the buckets.
+	srv, err = New(cfg, src, nil, false, WithTokenSecret("token-secret"), WithLimiters(limiters))
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	srv.forms["/contact"].sender = fake
+	honeypot.Set("_token", srv.tokens.Issue(time.Now().Add(-time.Minute)))
+	if rec := post(honeypot); rec.Code != http.StatusAccepted || len(fake.messages) != 1 {
+		t.Fatalf("expected rate limit to survive a reload, got %d and %d messages", rec.Code, len(fake.messages))
+	}
+}
+
+func TestContactSubmitRejectsReplayedToken(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+	cfg.Contact = config.Contact{
+		Backend: config.BackendWebhook,
+		Webhook: config.Webhook{URL: "https://hooks.example.test", Secret: "s3cret"},
+		Spam:    config.Spam{MinSeconds: 2},
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	srv, err := New(cfg, src, nil, false, WithTokenSecret("token-secret"))
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	fake := &fakeContactSender{enabled: true}
+	srv.forms["/contact"].sender = fake
+
+	form := url.Values{
+		"_token":  {srv.tokens.Issue(time.Now().Add(-time.Minute))},
+		"name":    {"Ada"},
+		"email":   {"ada@example.test"},
+		"message": {"Hello"},
+	}
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post(); rec.Code != http.StatusAccepted || len(fake.messages) != 1 {
+		t.Fatalf("expected delivery, got %d and %d messages", rec.Code, len(fake.messages))
+	}
+	if rec := post(); rec.Code != http.StatusAccepted || len(fake.messages) != 1 {
+		t.Fatalf("expected replayed token to be dropped, got %d and %d messages", rec.Code, len(fake.messages))
+	}
+}
+
+func TestContactSubmitCaptcha(t *testing.T) {
+	verify := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		_, _ = io.WriteString(w, `{"success":`+strconv.FormatBool(r.PostForm.Get("response") == "human")+`}`)
+	}))
+	t.Cleanup(verify.Close)
+
+	cfg, src := setupTestEnvironment(t)
+	cfg.Contact = config.Contact{
+		Backend: config.BackendWebhook,
+		Webhook: config.Webhook{URL: "https://hooks.example.test", Secret: "s3cret"},
+		Captcha: config.Captcha{Provider: config.CaptchaTurnstile, Secret: "shh", VerifyURL: verify.URL},
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	fake := &fakeContactSender{enabled: true}
+	srv.forms["/contact"].sender = fake
+
+	post := func(token string) *httptest.ResponseRecorder {
+		form := url.Values{"name": {"Jane"}, "email": {"jane@example.test"}, "message": {"Hi"}}
+		if token != "" {
+			form.Set("cf-turnstile-response", token)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	for token, code := range map[string]string{"": "captcha_required", "robot": "captcha_invalid"} {
+		rec := post(token)
+		var payload struct {
+			Code string `json:"code"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &payload)
+		if rec.Code != http.StatusBadRequest || payload.Code != code {
+			t.Fatalf("token %q: expected 400 %s, got %d %s", token, code, rec.Code, rec.Body.String())
+		}
+	}
+
+	if rec := post("human"); rec.Code != http.StatusAccepted || len(fake.messages) != 1 {
+		t.Fatalf("expected verified submission to be sent, got %d", rec.Code)
+	}
+}
+
+func TestContactSubmitCSRF(t *testing.T) {
+	tdir := t.TempDir()
+	webDir := filepath.Join(tdir, "web")
+
+	mustWrite(t, filepath.Join(webDir, "pages", "home.html"), `<!doctype html><html><body><h1>Home</h1></body></html>`)
+	mustWrite(t, filepath.Join(webDir, "pages", "contact.html"), `<!doctype html><html><body><form>{{ csrfField }}</form></body></html>`)
+
+	cfg := &config.Config{
+		Site: config.Site{BaseURL: "https://example.test"},
+		Routes: []config.Route{
+			{Path: "/", Page: "home.html"},
+			{Path: "/contact", Page: "contact.html"},
+		},
+		Contact: config.Contact{
+			Backend: config.BackendWebhook,
+			Webhook: config.Webhook{URL: "https://hooks.example.test", Secret: "s3cret"},
+		},
+		Security: config.Security{CSRF: config.CSRF{Tokens: true, AllowedOrigins: []string{"https://partner.example"}}},
+	}
+	cfg.WithLoadedTime(time.Now())
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	src, err := assets.NewDisk(webDir)
+	if err != nil {
+		t.Fatalf("new disk source: %v", err)
+	}
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	fake := &fakeContactSender{enabled: true}
+	srv.forms["/contact"].sender = fake
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contact", nil))
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "landing_csrf" || !cookies[0].HttpOnly || !cookies[0].Secure {
+		t.Fatalf("expected a secure nonce cookie, got %+v", cookies)
+	}
+	if rec.Header().Get("ETag") != "" || rec.Header().Get("Cache-Control") != "private, no-store" {
+		t.Fatalf("pages with tokens must not be cached: %v", rec.Header())
+	}
+	match := regexp.MustCompile(`name="_csrf" value="([^"]+)"`).FindStringSubmatch(rec.Body.String())
+	if match == nil || strings.Contains(match[1], "LANDING") {
+		t.Fatalf("expected a rendered token, got %s", rec.Body.String())
+	}
+	token := match[1]
+
+	post := func(token, origin string, withCookie bool) *httptest.ResponseRecorder {
+		form := url.Values{"name": {"Jane"}, "email": {"jane@example.test"}, "message": {"Hi"}}
+		if token != "" {
+			form.Set("_csrf", token)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if withCookie {
+			req.AddCookie(cookies[0])
+		}
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	for name, rec := range map[string]*httptest.ResponseRecorder{
+		"missing token":  post("", "https://example.test", true),
+		"missing cookie": post(token, "https://example.test", false),
+		"cross origin":   post(token, "https://evil.example", true),
+	} {
+		if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "403") {
+			t.Fatalf("%s: expected 403 page, got %d %s", name, rec.Code, rec.Body.String())
+		}
+	}
+	if len(fake.messages) != 0 {
+		t.Fatalf("rejected submissions must not be delivered: %+v", fake.messages)
+	}
+
+	if rec := post(token, "https://example.test", true); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected valid submission to be accepted, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := post("", "https://partner.example", false); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected allowlisted partner to be accepted, got %d %s", rec.Code, rec.Body.String())
+	}
+	if len(fake.messages) != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", len(fake.messages))
+	}
+}
+
+func TestSecurityHeadersAndCSPNonce(t *testing.T) {
+	tdir := t.TempDir()
+	webDir := filepath.Join(tdir, "web")
+
+	mustWrite(t, filepath.Join(webDir, "pages", "home.html"), `<!doctype html><html><body><script nonce="{{.Nonce}}">let x;</script></body></html>`)
+	mustWrite(t, filepath.Join(webDir, "pages", "about.html"), `<!doctype html><html><body><h1>About</h1></body></html>`)
+
+	cfg := &config.Config{
+		Site: config.Site{BaseURL: "https://example.test"},
+		Routes: []config.Route{
+			{Path: "/", Page: "home.html"},
+			{Path: "/about", Page: "about.html"},
+		},
+		Headers: map[string]map[string]string{"/about": {"X-Frame-Options": "SAMEORIGIN"}},
+		Security: config.Security{
+			Headers: config.SecurityHeaders{Enabled: true, HSTSPreload: true},
+			CSP:     config.CSP{Enabled: true, Directives: map[string]string{"img-src": "'self' https://images.example"}},
+		},
+	}
+	cfg.WithLoadedTime(time.Now())
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	src, err := assets.NewDisk(webDir)
+	if err != nil {
+		t.Fatalf("new disk source: %v", err)
+	}
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	nonceOf := func() (string, *httptest.ResponseRecorder) {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		match := regexp.MustCompile(`nonce="([^"]+)"`).FindStringSubmatch(rec.Body.String())
+		if match == nil || strings.Contains(match[1], "LANDING") {
+			t.Fatalf("expected a rendered nonce, got %s", rec.Body.String())
+		}
+		return match[1], rec
+	}
+
+	nonce, rec := nonceOf()
+	header := rec.Header()
+	policy := header.Get("Content-Security-Policy")
+	for _, want := range []string{"default-src 'self'", "script-src 'self' 'nonce-" + nonce + "'", "img-src 'self' https://images.example", "report-uri /csp-report"} {
+		if !strings.Contains(policy, want) {
+			t.Fatalf("policy %q missing %q", policy, want)
+		}
+	}
+	if header.Get("Strict-Transport-Security") != "max-age=31536000; preload" ||
+		header.Get("X-Content-Type-Options") != "nosniff" ||
+		header.Get("X-Frame-Options") != "DENY" ||
+		header.Get("Referrer-Policy") != config.DefaultReferrerPolicy {
+		t.Fatalf("unexpected security headers: %v", header)
+	}
+	if header.Get("ETag") != "" || header.Get("Cache-Control") != "private, no-store" {
+		t.Fatalf("pages with nonces must not be cached: %v", header)
+	}
+
+	if again, _ := nonceOf(); again == nonce {
+		t.Fatalf("cached page reused nonce %q", nonce)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/about", nil))
+	if rec.Header().Get("X-Frame-Options") != "SAMEORIGIN" || rec.Header().Get("ETag") == "" {
+		t.Fatalf("route headers should override defaults on cacheable pages: %v", rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rec.Code != http.StatusNotFound || rec.Header().Get("Content-Security-Policy") == "" {
+		t.Fatalf("error pages should carry the policy: %d %v", rec.Code, rec.Header())
+	}
+
+	report := `{"csp-report":{"document-uri":"https://example.test/","violated-directive":"script-src-elem","blocked-uri":"inline"}}`
+	req := httptest.NewRequest(http.MethodPost, "/csp-report", strings.NewReader(report))
+	req.Header.Set("Content-Type", "application/csp-report")
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("report: expected 204, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/csp-report", strings.NewReader("nope")))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("malformed report: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestHeaderRulesApplyToEveryResponse(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+	cfg.Headers = map[string]map[string]string{
+		"/**":          {"X-Site": "landing"},
+		"/static/**":   {"Cache-Control": "public, max-age=60", "X-Site": "static"},
+		"*.css":        {"Access-Control-Allow-Origin": "*"},
+		"/":            {"-X-Site": ""},
+		"/sitemap.xml": {"X-Robots-Tag": "noindex"},
+	}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	get := func(path string) http.Header {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, rec.Code)
+		}
+		return rec.Header()
+	}
+
+	if h := get("/static/app.css"); h.Get("X-Site") != "static" || h.Get("Cache-Control") != "public, max-age=60" || h.Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("static headers not applied: %v", h)
+	}
+	if h := get("/"); h.Get("X-Site") != "" {
+		t.Fatalf("exact rule should remove X-Site: %v", h)
+	}
+	if h := get("/sitemap.xml"); h.Get("X-Site") != "landing" || h.Get("X-Robots-Tag") != "noindex" {
+		t.Fatalf("sitemap headers not applied: %v", h)
+	}
+	if h := get("/robots.txt"); h.Get("X-Site") != "landing" {
+		t.Fatalf("robots headers not applied: %v", h)
+	}
+}
+
 func TestContactSubmitWithoutContactRoute(t *testing.T) {
 	tdir := t.TempDir()
 	webDir := filepath.Join(tdir, "web")
@@ -253,7 +1385,7 @@ func TestContactSubmitWithoutContactRoute(t *testing.T) {
 	}
 
 	fake := &fakeContactSender{enabled: true}
-	srv.contact = fake
+	srv.forms["/contact"].sender = fake
 
 	ts := httptest.NewServer(srv.Handler())
 	t.Cleanup(ts.Close)
@@ -286,8 +1418,8 @@ func TestContactSubmitWithoutContactRoute(t *testing.T) {
 		t.Fatalf("expected 404 when contact page missing, got %d", respGet.StatusCode)
 	}
 
-	if allow := respGet.Header.Get("Allow"); allow != "POST" {
-		t.Fatalf("expected Allow header to be POST, got %q", allow)
+	if allow := respGet.Header.Get("Allow"); allow != "" {
+		t.Fatalf("expected no Allow header on a 404, got %q", allow)
 	}
 }
 
@@ -340,6 +1472,20 @@ func (f *fakeContactSender) Send(_ context.Context, msg contact.Message) error {
 	return nil
 }
 
+func getBody(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("get %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read %s: %v", url, err)
+	}
+	return string(body)
+}
+
 func mustWrite(t *testing.T, path, content string) {
 	t.Helper()
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
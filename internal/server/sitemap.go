@@ -0,0 +1,145 @@
+package server
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/assets"
+	"github.com/elchemista/LandingGo/internal/config"
+	"github.com/elchemista/LandingGo/internal/pages"
+	"github.com/elchemista/LandingGo/internal/sitemap"
+)
+
+// buildSitemaps renders the sitemap files keyed by request path. Each
+// route's lastmod is taken from its page (and layout) modification time
+// rather than the process start time, and its images and videos from the
+// media the packer recorded for the page. Collection routes are listed once
+// per entry, dated by the later of the page and the entry file. Markdown
+// pages take their metadata from front matter, and drafts are left out; a
+// Markdown page that cannot be parsed fails the build.
+func buildSitemaps(cfg *config.Config, src *assets.Source, routes []config.Route, entries map[string]collectionEntry) (map[string]*pageEntry, error) {
+	listed, err := markdownRoutes(src, routes)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := sitemap.BuildFiles(cfg.Site.BaseURL, withEntries(listed, entries), sitemap.Options{
+		LastMod: func(rt config.Route) time.Time {
+			mod := pageModTime(src, rt.Page)
+			if ce, ok := entries[rt.Path]; ok {
+				if entryMod := fileModTime(src, ce.entry.File); entryMod.After(mod) {
+					mod = entryMod
+				}
+			}
+			return mod
+		},
+		Media: func(rt config.Route) sitemap.Media {
+			return pageMedia(src.Manifest, rt.Page)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]*pageEntry, len(files)+1)
+	for _, f := range files {
+		out["/"+f.Name] = &pageEntry{Body: f.Body, ETag: computeETag(f.Body), LastModified: f.LastMod}
+	}
+	out["/sitemap.xml"] = out["/"+files[0].Name]
+
+	return out, nil
+}
+
+// pageMedia converts the media recorded in the manifest for page, pointing
+// local assets at their fingerprinted URLs.
+func pageMedia(manifest *assets.Manifest, page string) sitemap.Media {
+	if manifest == nil {
+		return sitemap.Media{}
+	}
+	entry, ok := manifest.Files[path.Join("pages", page)]
+	if !ok || entry.Media == nil {
+		return sitemap.Media{}
+	}
+
+	var media sitemap.Media
+	for _, img := range entry.Media.Images {
+		media.Images = append(media.Images, pages.AssetURL(manifest, img))
+	}
+	for _, v := range entry.Media.Videos {
+		media.Videos = append(media.Videos, sitemap.Video{
+			Content:   pages.AssetURL(manifest, v.Content),
+			Thumbnail: pages.AssetURL(manifest, v.Thumbnail),
+			Title:     v.Title,
+		})
+	}
+
+	return media
+}
+
+// pageModTime returns the later of the page's and its layout's modification
+// times, preferring the packed manifest over the filesystem. It is zero when
+// neither is known.
+func pageModTime(src *assets.Source, page string) time.Time {
+	name := path.Join("pages", page)
+	mod := fileModTime(src, name)
+
+	if data, err := fs.ReadFile(src.FS, name); err == nil {
+		if layout := pages.PageLayout(page, data); layout != "" {
+			if layoutMod := fileModTime(src, path.Join("pages", layout)); layoutMod.After(mod) {
+				mod = layoutMod
+			}
+		}
+	}
+
+	return mod
+}
+
+func fileModTime(src *assets.Source, name string) time.Time {
+	if src.Manifest != nil {
+		if meta, ok := src.Manifest.Files[name]; ok && !meta.ModTime.IsZero() {
+			return meta.ModTime.UTC()
+		}
+	}
+	if mod, err := src.ModTime(name); err == nil {
+		return mod.UTC()
+	}
+	return time.Time{}
+}
+
+func (s *Server) serveSitemap(w http.ResponseWriter, r *http.Request) {
+	entry, ok := s.sitemaps[r.URL.Path]
+	if !ok {
+		s.serveNotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		s.writeStatus(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "application/xml")
+	header.Set("Cache-Control", "public, max-age=300")
+	s.applyHeaderRules(w, r.URL.Path)
+	s.applyCacheHeaders(w, entry.ETag, entry.LastModified)
+
+	if isNotModified(r, entry.ETag, entry.LastModified) {
+		s.writeStatus(w, http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(entry.Body)))
+
+	if r.Method == http.MethodHead {
+		s.writeStatus(w, http.StatusOK)
+		return
+	}
+
+	s.writeStatus(w, http.StatusOK)
+	_, _ = w.Write(entry.Body)
+}
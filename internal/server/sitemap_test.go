@@ -0,0 +1,91 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/assets"
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+func TestServeSitemap(t *testing.T) {
+	cfg, src := setupTestEnvironment(t)
+
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(src.Root(), "pages", "home.html"), modified, modified); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	mustWrite(t, filepath.Join(src.Root(), "pages", "404.html"), "<h1>Not found</h1>")
+	cfg.Routes = append(cfg.Routes, config.Route{Path: "/not-found", Page: "404.html"})
+	cfg.Redirects = []config.Redirect{{From: "/old", To: "/"}}
+	if err := cfg.Validate(func(string) bool { return true }); err != nil {
+		t.Fatalf("validate config: %v", err)
+	}
+
+	src.Manifest = &assets.Manifest{
+		Files: map[string]assets.ManifestEntry{
+			"pages/home.html": {Media: &assets.PageMedia{
+				Images: []string{"/static/hero.png"},
+				Videos: []assets.PageVideo{{Content: "/static/hero.mp4", Thumbnail: "/static/hero.png"}},
+			}},
+		},
+		Fingerprints: map[string]string{"static/hero.png": "static/hero.1a2b3c4d.png"},
+	}
+
+	srv, err := New(cfg, src, nil, false)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil)
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "<lastmod>2024-03-01T12:00:00Z</lastmod>") {
+		t.Fatalf("expected lastmod from page mod time, got %s", body)
+	}
+	for _, want := range []string{
+		"<image:loc>https://example.test/static/hero.1a2b3c4d.png</image:loc>",
+		"<video:content_loc>https://example.test/static/hero.mp4</video:content_loc>",
+		"<video:title>Home</video:title>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("missing %s in sitemap: %s", want, body)
+		}
+	}
+	for _, excluded := range []string{"/not-found", "/old"} {
+		if strings.Contains(body, "https://example.test"+excluded+"<") {
+			t.Fatalf("%s should not be listed: %s", excluded, body)
+		}
+	}
+
+	etag := rec.Header().Get("ETag")
+	if etag == "" || rec.Header().Get("Last-Modified") != modified.Format(http.TimeFormat) {
+		t.Fatalf("missing validators: etag=%q last-modified=%q", etag, rec.Header().Get("Last-Modified"))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("expected 304 with empty body, got %d (%d bytes)", rec.Code, rec.Body.Len())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/sitemap.xml", nil)
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for POST, got %d", rec.Code)
+	}
+}
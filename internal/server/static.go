@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+
+	"github.com/elchemista/LandingGo/internal/assets"
+	"github.com/elchemista/LandingGo/internal/middleware"
+)
+
+// writeAsset sends a cached asset honouring content negotiation, conditional
+// and range requests.
+func (s *Server) writeAsset(w http.ResponseWriter, r *http.Request, asset *assets.CachedAsset, cacheControl string) {
+	header := w.Header()
+	header.Set("Content-Type", asset.MIME)
+	header.Set("Cache-Control", cacheControl)
+	header.Set("Accept-Ranges", "bytes")
+	s.applyHeaderRules(w, r.URL.Path)
+
+	// Ranges always address the identity representation, so the
+	// precompressed variant is only offered to plain requests.
+	if len(asset.Gzip) > 0 {
+		header.Add("Vary", "Accept-Encoding")
+		if r.Header.Get("Range") == "" && middleware.AcceptsGzip(r) {
+			s.writePrecompressed(w, r, asset)
+			return
+		}
+	}
+
+	s.applyCacheHeaders(w, asset.ETag, asset.LastModified)
+
+	if isNotModified(r, asset.ETag, asset.LastModified) {
+		s.writeStatus(w, http.StatusNotModified)
+		return
+	}
+
+	size := int64(len(asset.Body))
+
+	var ranges []byteRange
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" && r.Method == http.MethodGet && ifRangeMatches(r, asset.ETag, asset.LastModified) {
+		parsed, err := parseRange(rangeHeader, size)
+		if errors.Is(err, errUnsatisfiableRange) {
+			disableCompression(w)
+			header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			header.Set("Content-Length", "0")
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		ranges = parsed
+	}
+
+	switch len(ranges) {
+	case 0:
+		header.Set("Content-Length", strconv.FormatInt(size, 10))
+		if r.Method == http.MethodHead {
+			s.writeStatus(w, http.StatusOK)
+			return
+		}
+		s.writeStatus(w, http.StatusOK)
+		_, _ = w.Write(asset.Body)
+	case 1:
+		br := ranges[0]
+		disableCompression(w)
+		header.Set("Content-Range", br.contentRange(size))
+		header.Set("Content-Length", strconv.FormatInt(br.length, 10))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(asset.Body[br.start : br.start+br.length])
+	default:
+		body, contentType := multipartRanges(asset, ranges, size)
+		disableCompression(w)
+		header.Set("Content-Type", contentType)
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(body)
+	}
+}
+
+// multipartRanges renders a multipart/byteranges payload for several ranges.
+func multipartRanges(asset *assets.CachedAsset, ranges []byteRange, size int64) ([]byte, string) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	for _, br := range ranges {
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":  {asset.MIME},
+			"Content-Range": {br.contentRange(size)},
+		})
+		if err != nil {
+			break
+		}
+		_, _ = part.Write(asset.Body[br.start : br.start+br.length])
+	}
+	_ = mw.Close()
+
+	return buf.Bytes(), "multipart/byteranges; boundary=" + mw.Boundary()
+}
+
+func (s *Server) writePrecompressed(w http.ResponseWriter, r *http.Request, asset *assets.CachedAsset) {
+	header := w.Header()
+	header.Set("Content-Encoding", "gzip")
+
+	s.applyCacheHeaders(w, asset.GzipETag, asset.LastModified)
+
+	if isNotModified(r, asset.GzipETag, asset.LastModified) {
+		s.writeStatus(w, http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(asset.Gzip)))
+
+	if r.Method == http.MethodHead {
+		s.writeStatus(w, http.StatusOK)
+		return
+	}
+
+	s.writeStatus(w, http.StatusOK)
+	_, _ = w.Write(asset.Gzip)
+}
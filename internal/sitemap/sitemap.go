@@ -4,17 +4,109 @@ import (
 	"encoding/xml"
 	"errors"
 	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/elchemista/LandingGo/internal/config"
 )
 
-const sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+const (
+	sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	xhtmlNS   = "http://www.w3.org/1999/xhtml"
+	imageNS   = "http://www.google.com/schemas/sitemap-image/1.1"
+	videoNS   = "http://www.google.com/schemas/sitemap-video/1.1"
+)
 
-// Build generates a sitemap XML document for the provided routes.
-func Build(baseURL string, routes []config.Route, generated time.Time) ([]byte, error) {
-	if baseURL == "" {
-		return nil, ErrBaseURLRequired
+// Protocol limits for a single sitemap file.
+const (
+	MaxURLs  = 50000
+	MaxBytes = 50 << 20
+)
+
+// IndexName is the file name of the sitemap index written when the URLs do
+// not fit in a single sitemap.
+const IndexName = "sitemap_index.xml"
+
+// Options supplies per-route data that lives outside the configuration.
+type Options struct {
+	// LastMod returns when a route's content last changed. A zero time omits
+	// the lastmod element.
+	LastMod func(config.Route) time.Time
+	// Media returns the images and videos shown on a route's page.
+	Media func(config.Route) Media
+	// MaxURLs and MaxBytes override the per-file limits used by BuildFiles.
+	// Zero means the protocol limits.
+	MaxURLs  int
+	MaxBytes int
+}
+
+// Media lists the images and videos on a page. URLs may be site-relative.
+type Media struct {
+	Images []string
+	Videos []Video
+}
+
+// Video is a video on a page. Videos without a thumbnail are omitted, as
+// the video extension requires one; Title and Description fall back to the
+// route's title and description.
+type Video struct {
+	Content     string
+	Thumbnail   string
+	Title       string
+	Description string
+}
+
+// File is one generated sitemap document, including the XML declaration.
+type File struct {
+	Name    string
+	Body    []byte
+	LastMod time.Time
+}
+
+// Build generates a sitemap XML document for the provided routes. Routes that
+// are not InSitemap, or whose canonical URL points elsewhere, are left out; a
+// route's hreflang alternates are listed as xhtml:link elements and its media
+// as image:image and video:video elements.
+func Build(baseURL string, routes []config.Route, opts Options) ([]byte, error) {
+	entries, _, err := buildEntries(baseURL, routes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return xml.MarshalIndent(newURLSet(entries), "", "  ")
+}
+
+// BuildFiles generates the sitemap as a set of files. When every URL fits in
+// one file within the protocol limits the result is a single "sitemap.xml";
+// otherwise the first file is IndexName, referencing children named
+// "sitemap-1.xml", "sitemap-2.xml" and so on.
+func BuildFiles(baseURL string, routes []config.Route, opts Options) ([]File, error) {
+	entries, mods, err := buildEntries(baseURL, routes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	maxURLs, maxBytes := opts.MaxURLs, opts.MaxBytes
+	if maxURLs <= 0 {
+		maxURLs = MaxURLs
+	}
+	if maxBytes <= 0 {
+		maxBytes = MaxBytes
+	}
+
+	chunks, err := split(entries, maxURLs, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(chunks) <= 1 {
+		body, err := marshalFile(newURLSet(entries))
+		if err != nil {
+			return nil, err
+		}
+		return []File{{Name: "sitemap.xml", Body: body, LastMod: latest(mods)}}, nil
 	}
 
 	base, err := url.Parse(baseURL)
@@ -22,40 +114,310 @@ func Build(baseURL string, routes []config.Route, generated time.Time) ([]byte,
 		return nil, err
 	}
 
+	files := make([]File, 0, len(chunks)+1)
+	index := sitemapIndex{XMLNS: sitemapNS}
+	offset := 0
+
+	for i, chunk := range chunks {
+		name := "sitemap-" + strconv.Itoa(i+1) + ".xml"
+		body, err := marshalFile(newURLSet(chunk))
+		if err != nil {
+			return nil, err
+		}
+
+		mod := latest(mods[offset : offset+len(chunk)])
+		offset += len(chunk)
+		files = append(files, File{Name: name, Body: body, LastMod: mod})
+
+		loc, err := resolve(base, "/"+name)
+		if err != nil {
+			return nil, err
+		}
+		ref := sitemapRef{Loc: loc}
+		if !mod.IsZero() {
+			ref.LastMod = mod.Format(time.RFC3339)
+		}
+		index.Sitemaps = append(index.Sitemaps, ref)
+	}
+
+	body, err := marshalFile(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]File{{Name: IndexName, Body: body, LastMod: latest(mods)}}, files...), nil
+}
+
+// buildEntries returns the URL entries for routes along with each entry's
+// modification time.
+func buildEntries(baseURL string, routes []config.Route, opts Options) ([]urlEntry, []time.Time, error) {
+	if baseURL == "" {
+		return nil, nil, ErrBaseURLRequired
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	entries := make([]urlEntry, 0, len(routes))
+	mods := make([]time.Time, 0, len(routes))
 
 	for _, rt := range routes {
-		ref, err := url.Parse(rt.Path)
+		if !rt.InSitemap() {
+			continue
+		}
+
+		loc, err := resolve(base, rt.Path)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		if rt.Canonical != "" {
+			canonical, err := resolve(base, rt.Canonical)
+			if err != nil {
+				return nil, nil, err
+			}
+			if canonical != loc {
+				continue
+			}
 		}
 
-		loc := base.ResolveReference(ref)
+		entry := urlEntry{
+			Loc:        loc,
+			ChangeFreq: rt.ChangeFreq,
+		}
+		var mod time.Time
+		if opts.LastMod != nil {
+			if mod = opts.LastMod(rt).UTC(); !mod.IsZero() {
+				entry.LastMod = mod.Format(time.RFC3339)
+			}
+		}
+		if rt.Priority != nil {
+			entry.Priority = strconv.FormatFloat(*rt.Priority, 'f', -1, 64)
+		}
+
+		if entry.Links, err = alternateLinks(base, rt, loc); err != nil {
+			return nil, nil, err
+		}
+
+		if opts.Media != nil {
+			if err := addMedia(&entry, base, rt, opts.Media(rt)); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		entries = append(entries, entry)
+		mods = append(mods, mod)
+	}
 
-		entries = append(entries, urlEntry{
-			Loc:     loc.String(),
-			LastMod: generated.UTC().Format(time.RFC3339),
+	return entries, mods, nil
+}
+
+func addMedia(entry *urlEntry, base *url.URL, rt config.Route, media Media) error {
+	for _, img := range media.Images {
+		loc, err := resolve(base, img)
+		if err != nil {
+			return err
+		}
+		entry.Images = append(entry.Images, imageEntry{Loc: loc})
+	}
+
+	for _, v := range media.Videos {
+		title := firstNonEmpty(v.Title, rt.Title)
+		if v.Content == "" || v.Thumbnail == "" || title == "" {
+			continue
+		}
+
+		content, err := resolve(base, v.Content)
+		if err != nil {
+			return err
+		}
+		thumb, err := resolve(base, v.Thumbnail)
+		if err != nil {
+			return err
+		}
+
+		entry.Videos = append(entry.Videos, videoEntry{
+			Thumbnail:   thumb,
+			Title:       title,
+			Description: firstNonEmpty(v.Description, rt.Description, title),
+			Content:     content,
 		})
 	}
 
+	return nil
+}
+
+// split groups entries into chunks that each fit in one sitemap file.
+func split(entries []urlEntry, maxURLs, maxBytes int) ([][]urlEntry, error) {
+	empty, err := marshalFile(urlSet{XMLNS: sitemapNS, XMLNSXhtml: xhtmlNS, XMLNSImage: imageNS, XMLNSVideo: videoNS})
+	if err != nil {
+		return nil, err
+	}
+	overhead := len(empty) + 1
+
+	var (
+		chunks [][]urlEntry
+		start  int
+		size   = overhead
+	)
+
+	for i, entry := range entries {
+		data, err := xml.MarshalIndent(entry, "  ", "  ")
+		if err != nil {
+			return nil, err
+		}
+		cost := len(data) + 1
+
+		if i > start && (i-start >= maxURLs || size+cost > maxBytes) {
+			chunks = append(chunks, entries[start:i])
+			start, size = i, overhead
+		}
+		size += cost
+	}
+
+	if start < len(entries) || len(chunks) == 0 {
+		chunks = append(chunks, entries[start:])
+	}
+
+	return chunks, nil
+}
+
+func newURLSet(entries []urlEntry) urlSet {
 	doc := urlSet{
 		XMLNS: sitemapNS,
 		URLs:  entries,
 	}
+	for _, e := range entries {
+		if len(e.Links) > 0 {
+			doc.XMLNSXhtml = xhtmlNS
+		}
+		if len(e.Images) > 0 {
+			doc.XMLNSImage = imageNS
+		}
+		if len(e.Videos) > 0 {
+			doc.XMLNSVideo = videoNS
+		}
+	}
+	return doc
+}
 
-	return xml.MarshalIndent(doc, "", "  ")
+func marshalFile(doc any) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+func latest(times []time.Time) time.Time {
+	var out time.Time
+	for _, t := range times {
+		if t.After(out) {
+			out = t
+		}
+	}
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// alternateLinks lists the route's translations sorted by hreflang. The route
+// itself is included under its own lang, as search engines expect every
+// version to reference all others.
+func alternateLinks(base *url.URL, rt config.Route, loc string) ([]xhtmlLink, error) {
+	if len(rt.Alternates) == 0 {
+		return nil, nil
+	}
+
+	hreflangs := make([]string, 0, len(rt.Alternates)+1)
+	for hreflang := range rt.Alternates {
+		hreflangs = append(hreflangs, hreflang)
+	}
+	if _, ok := rt.Alternates[rt.Lang]; rt.Lang != "" && !ok {
+		hreflangs = append(hreflangs, rt.Lang)
+	}
+	sort.Strings(hreflangs)
+
+	links := make([]xhtmlLink, 0, len(hreflangs))
+	for _, hreflang := range hreflangs {
+		href := loc
+		if target, ok := rt.Alternates[hreflang]; ok {
+			resolved, err := resolve(base, target)
+			if err != nil {
+				return nil, err
+			}
+			href = resolved
+		}
+		links = append(links, xhtmlLink{Rel: "alternate", Hreflang: hreflang, Href: href})
+	}
+
+	return links, nil
+}
+
+func resolve(base *url.URL, ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(u).String(), nil
 }
 
 // ErrBaseURLRequired indicates Build was called without a base URL.
 var ErrBaseURLRequired = errors.New("base URL is required")
 
 type urlSet struct {
-	XMLName xml.Name   `xml:"urlset"`
-	XMLNS   string     `xml:"xmlns,attr"`
-	URLs    []urlEntry `xml:"url"`
+	XMLName    xml.Name   `xml:"urlset"`
+	XMLNS      string     `xml:"xmlns,attr"`
+	XMLNSXhtml string     `xml:"xmlns:xhtml,attr,omitempty"`
+	XMLNSImage string     `xml:"xmlns:image,attr,omitempty"`
+	XMLNSVideo string     `xml:"xmlns:video,attr,omitempty"`
+	URLs       []urlEntry `xml:"url"`
 }
 
 type urlEntry struct {
+	XMLName    xml.Name     `xml:"url"`
+	Loc        string       `xml:"loc"`
+	LastMod    string       `xml:"lastmod,omitempty"`
+	ChangeFreq string       `xml:"changefreq,omitempty"`
+	Priority   string       `xml:"priority,omitempty"`
+	Links      []xhtmlLink  `xml:"xhtml:link"`
+	Images     []imageEntry `xml:"image:image"`
+	Videos     []videoEntry `xml:"video:video"`
+}
+
+type imageEntry struct {
+	Loc string `xml:"image:loc"`
+}
+
+type videoEntry struct {
+	Thumbnail   string `xml:"video:thumbnail_loc"`
+	Title       string `xml:"video:title"`
+	Description string `xml:"video:description"`
+	Content     string `xml:"video:content_loc"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	XMLNS    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
 	Loc     string `xml:"loc"`
 	LastMod string `xml:"lastmod,omitempty"`
 }
+
+type xhtmlLink struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
@@ -11,7 +11,7 @@ import (
 func TestBuildSitemap(t *testing.T) {
 	routes := []config.Route{{Path: "/"}, {Path: "/about"}}
 
-	data, err := Build("https://example.com", routes, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	data, err := Build("https://example.com", routes, at(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
 	if err != nil {
 		t.Fatalf("build sitemap: %v", err)
 	}
@@ -25,3 +25,190 @@ func TestBuildSitemap(t *testing.T) {
 		t.Fatalf("missing lastmod timestamp: %s", xml)
 	}
 }
+
+func TestBuildSitemapSEO(t *testing.T) {
+	routes := []config.Route{
+		{Path: "/", Lang: "it", Alternates: map[string]string{"en": "/en", "x-default": "/"}},
+		{Path: "/en", Lang: "en", Alternates: map[string]string{"it": "/"}},
+		{Path: "/thanks", NoIndex: true},
+		{Path: "/promo", Canonical: "/"},
+		{Path: "/self", Canonical: "https://example.com/self"},
+	}
+
+	data, err := Build("https://example.com", routes, at(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("build sitemap: %v", err)
+	}
+
+	xml := string(data)
+
+	for _, unwanted := range []string{"/thanks", "/promo"} {
+		if strings.Contains(xml, unwanted) {
+			t.Fatalf("sitemap should not list %s: %s", unwanted, xml)
+		}
+	}
+
+	if !strings.Contains(xml, "<loc>https://example.com/self</loc>") {
+		t.Fatalf("route with self-referencing canonical missing: %s", xml)
+	}
+
+	if !strings.Contains(xml, `xmlns:xhtml="http://www.w3.org/1999/xhtml"`) {
+		t.Fatalf("missing xhtml namespace: %s", xml)
+	}
+
+	for _, link := range []string{
+		`<xhtml:link rel="alternate" hreflang="en" href="https://example.com/en"></xhtml:link>`,
+		`<xhtml:link rel="alternate" hreflang="it" href="https://example.com/"></xhtml:link>`,
+		`<xhtml:link rel="alternate" hreflang="x-default" href="https://example.com/"></xhtml:link>`,
+	} {
+		if !strings.Contains(xml, link) {
+			t.Fatalf("missing alternate %s in %s", link, xml)
+		}
+	}
+
+	if got := strings.Count(xml, `hreflang="en"`); got != 2 {
+		t.Fatalf("expected the en page to list itself, got %d en links: %s", got, xml)
+	}
+}
+
+func at(ts time.Time) Options {
+	return Options{LastMod: func(config.Route) time.Time { return ts }}
+}
+
+func TestBuildSitemapRouteFields(t *testing.T) {
+	priority, precise := 0.8, 0.85
+	off := false
+	routes := []config.Route{
+		{Path: "/", Page: "home.html", ChangeFreq: "weekly", Priority: &priority},
+		{Path: "/about", Page: "about.html", Priority: &precise},
+		{Path: "/thanks", Page: "thanks.html", Sitemap: &off},
+		{Path: "/oops", Page: "404.html"},
+	}
+
+	modTimes := map[string]time.Time{
+		"home.html": time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+	}
+
+	data, err := Build("https://example.com", routes, Options{
+		LastMod: func(rt config.Route) time.Time { return modTimes[rt.Page] },
+	})
+	if err != nil {
+		t.Fatalf("build sitemap: %v", err)
+	}
+
+	want := `<url>
+    <loc>https://example.com/</loc>
+    <lastmod>2024-05-01T08:00:00Z</lastmod>
+    <changefreq>weekly</changefreq>
+    <priority>0.8</priority>
+  </url>
+  <url>
+    <loc>https://example.com/about</loc>
+    <priority>0.85</priority>
+  </url>`
+	if !strings.Contains(string(data), want) {
+		t.Fatalf("unexpected sitemap entries:\n%s", data)
+	}
+
+	for _, excluded := range []string{"/thanks", "/oops"} {
+		if strings.Contains(string(data), excluded) {
+			t.Fatalf("sitemap should not list %s: %s", excluded, data)
+		}
+	}
+}
+
+func TestBuildSitemapMedia(t *testing.T) {
+	routes := []config.Route{
+		{Path: "/", Title: "Home", Description: "Yachts"},
+		{Path: "/about", Title: "About"},
+	}
+
+	opts := Options{Media: func(rt config.Route) Media {
+		if rt.Path != "/" {
+			return Media{}
+		}
+		return Media{
+			Images: []string{"/static/img/hero.png", "https://cdn.example.com/boat.jpg"},
+			Videos: []Video{
+				{Content: "/static/img/video.mp4", Thumbnail: "/static/img/hero.png"},
+				{Content: "/static/img/no-poster.mp4"},
+			},
+		}
+	}}
+
+	data, err := Build("https://example.com", routes, opts)
+	if err != nil {
+		t.Fatalf("build sitemap: %v", err)
+	}
+	xml := string(data)
+
+	for _, want := range []string{
+		`xmlns:image="http://www.google.com/schemas/sitemap-image/1.1"`,
+		`xmlns:video="http://www.google.com/schemas/sitemap-video/1.1"`,
+		`<image:loc>https://example.com/static/img/hero.png</image:loc>`,
+		`<image:loc>https://cdn.example.com/boat.jpg</image:loc>`,
+		`<video:thumbnail_loc>https://example.com/static/img/hero.png</video:thumbnail_loc>`,
+		`<video:title>Home</video:title>`,
+		`<video:description>Yachts</video:description>`,
+		`<video:content_loc>https://example.com/static/img/video.mp4</video:content_loc>`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Fatalf("missing %s in sitemap: %s", want, xml)
+		}
+	}
+
+	if strings.Contains(xml, "no-poster.mp4") {
+		t.Fatalf("video without thumbnail should be omitted: %s", xml)
+	}
+}
+
+func TestBuildFilesSplitsIntoIndex(t *testing.T) {
+	routes := []config.Route{{Path: "/"}, {Path: "/a"}, {Path: "/b"}, {Path: "/c"}, {Path: "/d"}}
+	opts := at(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	files, err := BuildFiles("https://example.com", routes, opts)
+	if err != nil {
+		t.Fatalf("build files: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "sitemap.xml" || !strings.HasPrefix(string(files[0].Body), "<?xml") {
+		t.Fatalf("expected a single sitemap.xml, got %d files", len(files))
+	}
+
+	opts.MaxURLs = 2
+	files, err = BuildFiles("https://example.com", routes, opts)
+	if err != nil {
+		t.Fatalf("build files: %v", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	if strings.Join(names, ",") != "sitemap_index.xml,sitemap-1.xml,sitemap-2.xml,sitemap-3.xml" {
+		t.Fatalf("unexpected files: %v", names)
+	}
+
+	index := string(files[0].Body)
+	if !strings.Contains(index, "<sitemapindex") || !strings.Contains(index, "<loc>https://example.com/sitemap-3.xml</loc>") {
+		t.Fatalf("unexpected index: %s", index)
+	}
+	if strings.Count(string(files[1].Body), "<url>") != 2 || strings.Count(string(files[3].Body), "<url>") != 1 {
+		t.Fatalf("unexpected split:\n%s\n%s", files[1].Body, files[3].Body)
+	}
+
+	// A byte limit too small for two entries forces one URL per file.
+	opts.MaxURLs = 0
+	opts.MaxBytes = 400
+	files, err = BuildFiles("https://example.com", routes, opts)
+	if err != nil {
+		t.Fatalf("build files: %v", err)
+	}
+	if len(files) != len(routes)+1 {
+		t.Fatalf("expected %d files with byte limit, got %d", len(routes)+1, len(files))
+	}
+	for _, f := range files[1:] {
+		if len(f.Body) > opts.MaxBytes {
+			t.Fatalf("%s exceeds byte limit: %d", f.Name, len(f.Body))
+		}
+	}
+}
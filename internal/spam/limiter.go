@@ -0,0 +1,155 @@
+package spam
+
+import (
+	"sync"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+// maxIdle is how long a full bucket is kept before it is forgotten.
+const maxIdle = 10 * time.Minute
+
+// Limiter is a per-key token bucket.
+type Limiter struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a Limiter allowing burst requests at once per key,
+// refilled at perMinute.
+func NewLimiter(perMinute float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:    perMinute / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from key's bucket and reports whether one was
+// available.
+func (l *Limiter) Allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > maxIdle {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(l.burst, b.tokens+elapsed*l.rate)
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Limiters keeps one Limiter per form, and the time-trap tokens already
+// spent, across server generations, so a configuration reload neither hands
+// every client a fresh bucket nor lets a used token be replayed.
+type Limiters struct {
+	mu     sync.Mutex
+	byForm map[string]*Limiter
+
+	spent      map[string]time.Time // token -> expiry
+	lastExpiry time.Time
+}
+
+// NewLimiters returns an empty set of limiters.
+func NewLimiters() *Limiters {
+	return &Limiters{
+		byForm: make(map[string]*Limiter),
+		spent:  make(map[string]time.Time),
+	}
+}
+
+// For returns the named form's limiter for cfg, or nil when rate limiting is
+// off. The existing limiter is kept while its rate and burst are unchanged.
+// A nil Limiters returns a new limiter on every call.
+func (l *Limiters) For(form string, cfg config.RateLimit) *Limiter {
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	next := NewLimiter(cfg.PerMinute, cfg.Burst)
+	if l == nil {
+		return next
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if cur, ok := l.byForm[form]; ok && cur.rate == next.rate && cur.burst == next.burst {
+		return cur
+	}
+	l.byForm[form] = next
+	return next
+}
+
+// Spent reports whether token was already used by an accepted submission.
+func (l *Limiters) Spent(token string) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.spent[token]
+	return ok
+}
+
+// Spend marks token as used and reports whether it was still unused. Tokens
+// are forgotten once they would have expired anyway.
+func (l *Limiters) Spend(token string, now time.Time) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastExpiry) > maxIdle {
+		for key, expiry := range l.spent {
+			if now.After(expiry) {
+				delete(l.spent, key)
+			}
+		}
+		l.lastExpiry = now
+	}
+
+	if _, ok := l.spent[token]; ok {
+		return false
+	}
+	l.spent[token] = now.Add(MaxTokenAge)
+	return true
+}
+
+// sweep drops buckets that have refilled completely.
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst && now.Sub(b.last) > maxIdle {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,121 @@
+package spam
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+// Rejection reasons reported by Filter.
+const (
+	ReasonRateLimited  = "rate_limited"
+	ReasonHoneypot     = "honeypot"
+	ReasonNoToken      = "token_missing"
+	ReasonBadToken     = "token_invalid"
+	ReasonReplayed     = "token_replayed"
+	ReasonTooFast      = "too_fast"
+	ReasonBlocklist    = "blocklist"
+	ReasonTooManyLinks = "too_many_links"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)https?://|www\.`)
+
+// Filter applies a form's spam settings to submissions.
+type Filter struct {
+	cfg      config.Spam
+	signer   *Signer
+	limiter  *Limiter
+	limiters *Limiters
+}
+
+// New returns a Filter for the named form's cfg. Tokens are verified with
+// signer; limiters holds the rate limit and the spent tokens. With nil
+// limiters, rate limits start afresh and tokens are not checked for replay.
+func New(cfg config.Spam, signer *Signer, limiters *Limiters, form string) *Filter {
+	return &Filter{
+		cfg:      cfg,
+		signer:   signer,
+		limiter:  limiters.For(form, cfg.RateLimit),
+		limiters: limiters,
+	}
+}
+
+// Allow reports whether the client at ip is within the rate limit.
+func (f *Filter) Allow(ip string, now time.Time) bool {
+	if f == nil || f.limiter == nil {
+		return true
+	}
+	return f.limiter.Allow(ip, now)
+}
+
+// Check inspects the submitted values and returns the reason they look like
+// spam, or "" when they pass.
+func (f *Filter) Check(values url.Values, now time.Time) string {
+	if f == nil {
+		return ""
+	}
+
+	if f.cfg.Honeypot != "" && strings.TrimSpace(values.Get(f.cfg.Honeypot)) != "" {
+		return ReasonHoneypot
+	}
+
+	if f.cfg.MinSeconds > 0 {
+		token := values.Get(TokenField)
+		if token == "" {
+			return ReasonNoToken
+		}
+		age, err := f.signer.Age(token, now)
+		if err != nil {
+			return ReasonBadToken
+		}
+		if age < time.Duration(f.cfg.MinSeconds)*time.Second {
+			return ReasonTooFast
+		}
+		if f.limiters.Spent(token) {
+			return ReasonReplayed
+		}
+	}
+
+	if len(f.cfg.Blocklist) == 0 && f.cfg.MaxLinks == nil {
+		return ""
+	}
+
+	links := 0
+	for name, vals := range values {
+		if name == TokenField {
+			continue
+		}
+		for _, v := range vals {
+			lower := strings.ToLower(v)
+			for _, term := range f.cfg.Blocklist {
+				if strings.Contains(lower, term) {
+					return ReasonBlocklist
+				}
+			}
+			links += len(linkPattern.FindAllStringIndex(v, -1))
+		}
+	}
+	if f.cfg.MaxLinks != nil && links > *f.cfg.MaxLinks {
+		return ReasonTooManyLinks
+	}
+
+	return ""
+}
+
+// Consume spends the submission's time-trap token once it has been accepted,
+// and reports false when a concurrent submission already spent it. Tokens are
+// only spent on acceptance so a visitor correcting a field error can resubmit.
+func (f *Filter) Consume(values url.Values, now time.Time) bool {
+	if !f.UsesToken() {
+		return true
+	}
+	return f.limiters.Spend(values.Get(TokenField), now)
+}
+
+// UsesToken reports whether the filter requires a time-trap token.
+func (f *Filter) UsesToken() bool {
+	return f != nil && f.cfg.MinSeconds > 0
+}
@@ -0,0 +1,157 @@
+package spam
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/elchemista/LandingGo/internal/config"
+)
+
+func TestSignerAge(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	signer := NewSigner("secret")
+	token := signer.Issue(now)
+
+	age, err := signer.Age(token, now.Add(5*time.Second))
+	if err != nil || age != 5*time.Second {
+		t.Fatalf("unexpected age %v (%v)", age, err)
+	}
+
+	for name, tok := range map[string]string{
+		"forged":  "1700000000.AAAA",
+		"resign":  NewSigner("other").Issue(now),
+		"garbage": "nope",
+	} {
+		if _, err := signer.Age(tok, now); err != ErrInvalidToken {
+			t.Fatalf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+	if _, err := signer.Age(token, now.Add(MaxTokenAge+time.Second)); err != ErrInvalidToken {
+		t.Fatalf("expected expired token to be rejected, got %v", err)
+	}
+
+	if NewSigner("").Issue(now) != NewSigner("").Issue(now) {
+		t.Fatal("signers without a secret should share the process key")
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := NewLimiter(6, 2) // one token every 10 seconds
+
+	if !l.Allow("a", now) || !l.Allow("a", now) {
+		t.Fatal("burst should be allowed")
+	}
+	if l.Allow("a", now) {
+		t.Fatal("third request should be limited")
+	}
+	if !l.Allow("b", now) {
+		t.Fatal("keys must not share buckets")
+	}
+	if !l.Allow("a", now.Add(10*time.Second)) || l.Allow("a", now.Add(10*time.Second)) {
+		t.Fatal("bucket should refill one token after ten seconds")
+	}
+
+	l.Allow("a", now.Add(time.Hour))
+	if len(l.buckets) != 1 {
+		t.Fatalf("expected idle buckets to be swept, have %d", len(l.buckets))
+	}
+}
+
+func TestLimitersSurviveReload(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limiters := NewLimiters()
+	rate := config.RateLimit{PerMinute: 1, Burst: 1}
+
+	first := limiters.For("contact", rate)
+	if !first.Allow("a", now) {
+		t.Fatal("first request should be allowed")
+	}
+
+	if again := limiters.For("contact", rate); again != first || again.Allow("a", now) {
+		t.Fatal("an unchanged rate limit should keep its buckets")
+	}
+	if other := limiters.For("quote", rate); other == first || !other.Allow("a", now) {
+		t.Fatal("forms must not share limiters")
+	}
+	if changed := limiters.For("contact", config.RateLimit{PerMinute: 2, Burst: 1}); changed == first || !changed.Allow("a", now) {
+		t.Fatal("a changed rate limit should start fresh")
+	}
+	if limiters.For("contact", config.RateLimit{}) != nil {
+		t.Fatal("a disabled rate limit needs no limiter")
+	}
+}
+
+func TestFilterRejectsReplayedTokens(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	signer := NewSigner("secret")
+	limiters := NewLimiters()
+	f := New(config.Spam{MinSeconds: 3}, signer, limiters, "contact")
+
+	values := url.Values{TokenField: {signer.Issue(now.Add(-time.Minute))}}
+	if got := f.Check(values, now); got != "" {
+		t.Fatalf("fresh token rejected: %q", got)
+	}
+	if !f.Consume(values, now) {
+		t.Fatal("first use should spend the token")
+	}
+
+	// The token stays spent for a filter built by a later generation.
+	reloaded := New(config.Spam{MinSeconds: 3}, signer, limiters, "contact")
+	if got := reloaded.Check(values, now.Add(time.Minute)); got != ReasonReplayed {
+		t.Fatalf("replayed token: got %q, want %q", got, ReasonReplayed)
+	}
+	if reloaded.Consume(values, now) {
+		t.Fatal("a spent token cannot be consumed twice")
+	}
+
+	stale := url.Values{TokenField: {signer.Issue(now.Add(-MaxTokenAge - time.Second))}}
+	if got := f.Check(stale, now); got != ReasonBadToken {
+		t.Fatalf("stale token: got %q, want %q", got, ReasonBadToken)
+	}
+}
+
+func TestFilterCheck(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	signer := NewSigner("secret")
+	maxLinks := 1
+	f := New(config.Spam{
+		Honeypot:   "company",
+		MinSeconds: 3,
+		Blocklist:  []string{"casino"},
+		MaxLinks:   &maxLinks,
+	}, signer, nil, "contact")
+
+	valid := func() url.Values {
+		return url.Values{
+			"message":  {"See https://example.com"},
+			TokenField: {signer.Issue(now.Add(-time.Minute))},
+		}
+	}
+
+	cases := map[string]struct {
+		edit func(url.Values)
+		want string
+	}{
+		"clean":    {func(url.Values) {}, ""},
+		"honeypot": {func(v url.Values) { v.Set("company", "Acme") }, ReasonHoneypot},
+		"no token": {func(v url.Values) { v.Del(TokenField) }, ReasonNoToken},
+		"forged":   {func(v url.Values) { v.Set(TokenField, "1.x") }, ReasonBadToken},
+		"too fast": {func(v url.Values) { v.Set(TokenField, signer.Issue(now.Add(-time.Second))) }, ReasonTooFast},
+		"keyword":  {func(v url.Values) { v.Set("message", "Best CASINO bonus") }, ReasonBlocklist},
+		"links":    {func(v url.Values) { v.Set("message", "http://a.example www.b.example") }, ReasonTooManyLinks},
+	}
+
+	for name, tc := range cases {
+		values := valid()
+		tc.edit(values)
+		if got := f.Check(values, now); got != tc.want {
+			t.Fatalf("%s: got %q, want %q", name, got, tc.want)
+		}
+	}
+
+	if New(config.Spam{}, signer, nil, "contact").Check(url.Values{"company": {"x"}}, now) != "" {
+		t.Fatal("an empty configuration should accept everything")
+	}
+}
@@ -0,0 +1,77 @@
+package spam
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenField is the form parameter that carries the time-trap token.
+const TokenField = "_token"
+
+// MaxTokenAge bounds how long an issued token stays valid: long enough to
+// fill in a form, short enough that a harvested token goes stale quickly.
+const MaxTokenAge = 2 * time.Hour
+
+// ErrInvalidToken is returned for tokens that are malformed, forged or
+// expired.
+var ErrInvalidToken = errors.New("invalid form token")
+
+var (
+	processKeyOnce sync.Once
+	processKey     []byte
+)
+
+// Signer issues and verifies signed timestamps.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner returns a Signer keyed with secret. An empty secret uses a random
+// key shared by the whole process, so tokens survive config reloads but not
+// restarts, and are not accepted by other instances.
+func NewSigner(secret string) *Signer {
+	if secret != "" {
+		return &Signer{key: []byte(secret)}
+	}
+	processKeyOnce.Do(func() {
+		processKey = make([]byte, 32)
+		_, _ = rand.Read(processKey)
+	})
+	return &Signer{key: processKey}
+}
+
+// Issue returns a token recording now.
+func (s *Signer) Issue(now time.Time) string {
+	ts := strconv.FormatInt(now.Unix(), 10)
+	return ts + "." + s.sign(ts)
+}
+
+// Age verifies token and returns how long ago it was issued.
+func (s *Signer) Age(token string, now time.Time) (time.Duration, error) {
+	ts, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(ts))) {
+		return 0, ErrInvalidToken
+	}
+	issued, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	age := now.Sub(time.Unix(issued, 0))
+	if age < 0 || age > MaxTokenAge {
+		return 0, ErrInvalidToken
+	}
+	return age, nil
+}
+
+func (s *Signer) sign(ts string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(ts))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,120 @@
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultInterval is the polling interval used when none is supplied.
+const DefaultInterval = time.Second
+
+// Poller detects file changes beneath a root by periodically comparing
+// modification times and sizes. It relies only on the standard library so it
+// works the same on every platform without extra dependencies.
+type Poller struct {
+	root     string
+	interval time.Duration
+	state    map[string]fileState
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// New constructs a Poller for root, which may be a single file or a directory.
+func New(root string, interval time.Duration) *Poller {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	p := &Poller{root: root, interval: interval}
+	p.state = p.scan()
+	return p
+}
+
+// Run polls until ctx is cancelled, invoking onChange with the slash-separated
+// paths (relative to the root) that were added, modified or removed.
+func (p *Poller) Run(ctx context.Context, onChange func(changed []string)) {
+	if p == nil || onChange == nil {
+		return
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if changed := p.Poll(); len(changed) > 0 {
+				onChange(changed)
+			}
+		}
+	}
+}
+
+// Poll rescans the root once and returns the paths that changed since the
+// previous scan.
+func (p *Poller) Poll() []string {
+	if p == nil {
+		return nil
+	}
+
+	next := p.scan()
+	var changed []string
+
+	for name, st := range next {
+		prev, ok := p.state[name]
+		if !ok || !prev.modTime.Equal(st.modTime) || prev.size != st.size {
+			changed = append(changed, name)
+		}
+	}
+
+	for name := range p.state {
+		if _, ok := next[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+
+	p.state = next
+	sort.Strings(changed)
+
+	return changed
+}
+
+func (p *Poller) scan() map[string]fileState {
+	state := make(map[string]fileState)
+
+	info, err := os.Stat(p.root)
+	if err != nil {
+		return state
+	}
+
+	if !info.IsDir() {
+		state[filepath.Base(p.root)] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return state
+	}
+
+	_ = filepath.WalkDir(p.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(p.root, path)
+		if err != nil {
+			return nil
+		}
+		state[filepath.ToSlash(rel)] = fileState{modTime: fi.ModTime(), size: fi.Size()}
+		return nil
+	})
+
+	return state
+}
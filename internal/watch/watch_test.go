@@ -0,0 +1,55 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPollDetectsChanges(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "pages", "home.html"), "home")
+	writeFile(t, filepath.Join(root, "static", "app.css"), "body{}")
+
+	p := New(root, time.Hour)
+
+	if changed := p.Poll(); len(changed) != 0 {
+		t.Fatalf("expected no changes, got %v", changed)
+	}
+
+	writeFile(t, filepath.Join(root, "static", "app.css"), "body{color:red}")
+	writeFile(t, filepath.Join(root, "static", "new.js"), "1")
+	if err := os.Remove(filepath.Join(root, "pages", "home.html")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	want := []string{"pages/home.html", "static/app.css", "static/new.js"}
+	if changed := p.Poll(); !reflect.DeepEqual(changed, want) {
+		t.Fatalf("unexpected changes: want %v got %v", want, changed)
+	}
+}
+
+func TestPollSingleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, "{}")
+
+	p := New(path, time.Hour)
+
+	writeFile(t, path, `{"site":{}}`)
+
+	if changed := p.Poll(); !reflect.DeepEqual(changed, []string{"config.json"}) {
+		t.Fatalf("expected config change, got %v", changed)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}